@@ -23,6 +23,8 @@ var rootCmd = &cobra.Command{
 var (
 	cfgFile  string
 	replacer = strings.NewReplacer("-", "_", ".", "_")
+	// cfgErr keeps the error produced while reading the config file.
+	cfgErr error
 )
 
 const (
@@ -39,6 +41,10 @@ func init() {
 	rootCmd.AddCommand(commitCmd)
 	rootCmd.AddCommand(hookCmd)
 	rootCmd.AddCommand(reviewCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(promptCmd)
+	rootCmd.AddCommand(lintMessageCmd)
+	rootCmd.AddCommand(usageCmd)
 
 	// hide completion command
 	rootCmd.CompletionOptions.HiddenDefaultCmd = true
@@ -96,6 +102,7 @@ func initConfig() {
 			}
 		} else {
 			// Config file was found but another error was produced
+			cfgErr = err
 			fmt.Fprintln(os.Stderr, err)
 		}
 	}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/lint"
 	"github.com/appleboy/CodeGPT/openai"
 	"github.com/appleboy/CodeGPT/prompt"
 	"github.com/appleboy/CodeGPT/util"
@@ -30,6 +31,8 @@ var (
 	templateString string
 	commitAmend    bool
 	timeout        time.Duration
+	commitChoices  int
+	interactive    bool
 )
 
 func init() {
@@ -45,26 +48,85 @@ func init() {
 	commitCmd.PersistentFlags().StringVar(&templateString, "template_string", "", "git commit message string")
 	commitCmd.PersistentFlags().BoolVar(&commitAmend, "amend", false, "replace the tip of the current branch by creating a new commit.")
 	commitCmd.PersistentFlags().DurationVarP(&timeout, "timeout", "t", 10*time.Second, "http timeout")
+	commitCmd.PersistentFlags().IntVar(&commitChoices, "choices", 1, "generate <n> candidate commit messages to choose from")
+	commitCmd.PersistentFlags().BoolVarP(&interactive, "interactive", "i", false, "choose, edit or regenerate the commit message before committing")
+	commitCmd.PersistentFlags().Bool("signoff", true, "add a Signed-off-by trailer to the commit message")
+	commitCmd.PersistentFlags().String("gpg_sign", "", "sign the commit with GPG or SSH: true, false or the key ID")
+	commitCmd.PersistentFlags().Bool("verify", false, "run the pre-commit and commit-msg hooks when committing")
+	commitCmd.PersistentFlags().String("author", "", "override the commit author, ex: \"A U Thor <author@example.com>\"")
+	commitCmd.PersistentFlags().StringSlice("co_author", []string{}, "add a Co-authored-by trailer, ex: \"A U Thor <author@example.com>\"")
+	commitCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print the prompts and estimate the tokens and cost without calling the model")
+	commitCmd.PersistentFlags().StringVar(&dryRunDir, "dry-run-dir", "", "write the prompts of the dry run to the folder")
 	_ = viper.BindPFlag("output.file", commitCmd.PersistentFlags().Lookup("file"))
+	_ = viper.BindPFlag("commit.signoff", commitCmd.PersistentFlags().Lookup("signoff"))
+	_ = viper.BindPFlag("commit.gpg_sign", commitCmd.PersistentFlags().Lookup("gpg_sign"))
+	_ = viper.BindPFlag("commit.verify", commitCmd.PersistentFlags().Lookup("verify"))
+	_ = viper.BindPFlag("commit.author", commitCmd.PersistentFlags().Lookup("author"))
+	_ = viper.BindPFlag("commit.co_authors", commitCmd.PersistentFlags().Lookup("co_author"))
 }
 
 var commitCmd = &cobra.Command{
 	Use:   "commit",
 	Short: "Auto generate commit message",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// --choices 1 overrides a commit.choices config above 1
+		if cmd.Flags().Changed("choices") {
+			viper.Set("commit.choices", commitChoices)
+		}
 		if err := check(); err != nil {
 			return err
 		}
 
-		g := git.New(
+		g, err := newRepository(
 			git.WithDiffUnified(viper.GetInt("git.diff_unified")),
 			git.WithExcludeList(viper.GetStringSlice("git.exclude_list")),
+			git.WithIncludeGenerated(viper.GetBool("git.include_generated")),
 			git.WithEnableAmend(commitAmend),
+			git.WithSignoff(viper.GetBool("commit.signoff")),
+			git.WithGPGSign(viper.GetString("commit.gpg_sign")),
+			git.WithVerify(viper.GetBool("commit.verify")),
+			git.WithAuthor(viper.GetString("commit.author")),
+			git.WithCoAuthors(listConfig("commit.co_authors")),
 		)
+		if err != nil {
+			return err
+		}
 		diff, err := g.DiffFiles()
 		if err != nil {
 			return err
 		}
+		diff, err = redactDiff(diff)
+		if err != nil {
+			return err
+		}
+		changes, err := git.ParseDiff(diff)
+		if err != nil {
+			return err
+		}
+
+		// extract ticket IDs from the current branch name
+		issueKeys := []string{}
+		if viper.GetString("commit.issue_pattern") != "" {
+			branch, err := g.CurrentBranch()
+			if err != nil {
+				return err
+			}
+			issueKeys, err = git.IssueKeys(branch, viper.GetString("commit.issue_pattern"))
+			if err != nil {
+				return err
+			}
+		}
+
+		// learn the commit style from the repository history
+		style := git.CommitStyle{Conventional: true}
+		if size := viper.GetInt("commit.history_size"); size > 0 {
+			messages, err := g.Log(size)
+			if err != nil {
+				color.Yellow("Skip learning the commit style from the repository history: " + err.Error())
+			} else if len(messages) > 0 {
+				style = git.DetectCommitStyle(messages)
+			}
+		}
 
 		// Update the OpenAI client request timeout if the timeout value is greater than the default openai.timeout
 		if timeout > viper.GetDuration("openai.timeout") {
@@ -72,25 +134,18 @@ var commitCmd = &cobra.Command{
 		}
 
 		color.Green("Summarize the commit message use " + viper.GetString("openai.model") + " model")
-		client, err := openai.New(
-			openai.WithToken(viper.GetString("openai.api_key")),
-			openai.WithModel(viper.GetString("openai.model")),
-			openai.WithOrgID(viper.GetString("openai.org_id")),
-			openai.WithProxyURL(viper.GetString("openai.proxy")),
-			openai.WithSocksURL(viper.GetString("openai.socks")),
-			openai.WithBaseURL(viper.GetString("openai.base_url")),
-			openai.WithTimeout(viper.GetDuration("openai.timeout")),
-			openai.WithMaxTokens(viper.GetInt("openai.max_tokens")),
-			openai.WithTemperature(float32(viper.GetFloat64("openai.temperature"))),
-		)
+		client, err := newChatClient("commit")
 		if err != nil {
 			return err
 		}
 
-		out, err := util.GetTemplateByString(
+		messages, err := promptMessages(
+			"commit",
 			prompt.SummarizeFileDiffTemplate,
 			util.Data{
 				"file_diffs": diff,
+				"diff_files": changes.Files,
+				"diff_stat":  changes.Stat(),
 			},
 		)
 		if err != nil {
@@ -99,7 +154,7 @@ var commitCmd = &cobra.Command{
 
 		// Get summarize comment from diff datas
 		color.Cyan("We are trying to summarize a git diff")
-		resp, err := client.Completion(cmd.Context(), out)
+		resp, err := client.Chat(cmd.Context(), messages, 1)
 		if err != nil {
 			return err
 		}
@@ -109,103 +164,337 @@ var commitCmd = &cobra.Command{
 			", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
 		)
 
-		out, err = util.GetTemplateByString(
+		// keep the conversation for regenerating the commit message with feedback
+		conversation := append([]openai.Message{}, messages...)
+		conversation = append(conversation, openai.Message{Role: openai.RoleAssistant, Content: summarizeMessage})
+
+		// support conventional commits unless the repository history doesn't use them
+		summarizePrefix, summarizeScope := "", ""
+		if style.Conventional {
+			messages, err = promptMessages(
+				"commit",
+				prompt.ConventionalCommitTemplate,
+				util.Data{
+					"summary_points": summarizeMessage,
+				},
+			)
+			if err != nil {
+				return err
+			}
+			color.Cyan("We are trying to get conventional commit prefix")
+			resp, err = client.Chat(cmd.Context(), messages, 1)
+			if err != nil {
+				return err
+			}
+			summarizePrefix = resp.Content
+			color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
+				", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
+				", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
+			)
+
+			// detect conventional commit scope from the changed paths
+			files, err := g.DiffNames()
+			if err != nil {
+				return err
+			}
+			summarizeScope = git.DetectScope(files, git.ParseScopeRules(listConfig("commit.scopes")))
+			if summarizeScope == "" {
+				messages, err = promptMessages(
+					"commit",
+					prompt.ConventionalScopeTemplate,
+					util.Data{
+						"file_names":     strings.Join(files, "\n"),
+						"summary_points": summarizeMessage,
+					},
+				)
+				if err != nil {
+					return err
+				}
+				color.Cyan("We are trying to get conventional commit scope")
+				resp, err = client.Chat(cmd.Context(), messages, 1)
+				if err != nil {
+					return err
+				}
+				summarizeScope = strings.ToLower(strings.Trim(strings.TrimSpace(resp.Content), "`'\"."))
+				if summarizeScope == "none" || strings.ContainsAny(summarizeScope, " \n") {
+					summarizeScope = ""
+				}
+				color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
+					", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
+					", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
+				)
+			}
+		}
+
+		// render the gitmoji of the conventional commit label or ask the model to choose one
+		commitStyle := viper.GetString("commit.style")
+		if commitStyle == "" && style.Gitmoji {
+			commitStyle = prompt.GitmojiStyle
+		}
+		summarizeGitmoji := ""
+		if commitStyle == prompt.GitmojiStyle {
+			if !viper.GetBool("commit.gitmoji_model") {
+				summarizeGitmoji = prompt.GetGitmoji(summarizePrefix)
+			}
+			if summarizeGitmoji == "" {
+				messages, err = promptMessages(
+					"commit",
+					prompt.GitmojiTemplate,
+					util.Data{
+						"gitmojis":       prompt.Gitmojis,
+						"summary_points": summarizeMessage,
+					},
+				)
+				if err != nil {
+					return err
+				}
+				color.Cyan("We are trying to get gitmoji")
+				resp, err = client.Chat(cmd.Context(), messages, 1)
+				if err != nil {
+					return err
+				}
+				summarizeGitmoji = prompt.FindGitmoji(resp.Content)
+				color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
+					", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
+					", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
+				)
+			}
+		}
+
+		// detect breaking changes with the model when exported identifiers are removed,
+		// or for every commit with commit.detect_breaking, the identifiers are only a hint
+		summarizeBreaking := ""
+		removedIdentifiers := git.RemovedExportedIdentifiers(diff)
+		if len(removedIdentifiers) > 0 || viper.GetBool("commit.detect_breaking") {
+			messages, err = promptMessages(
+				"commit",
+				prompt.BreakingChangeTemplate,
+				util.Data{
+					"removed_identifiers": strings.Join(removedIdentifiers, "\n"),
+					"summary_points":      summarizeMessage,
+				},
+			)
+			if err != nil {
+				return err
+			}
+			color.Cyan("We are trying to detect breaking changes")
+			resp, err = client.Chat(cmd.Context(), messages, 1)
+			if err != nil {
+				return err
+			}
+			summarizeBreaking = prompt.ParseBreakingChange(resp.Content)
+			color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
+				", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
+				", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
+			)
+		}
+
+		data := util.Data{
+			"summarize_prefix":   strings.TrimSpace(summarizePrefix),
+			"summarize_scope":    summarizeScope,
+			"summarize_breaking": summarizeBreaking,
+			"summarize_gitmoji":  summarizeGitmoji,
+			"summarize_message":  strings.TrimSpace(summarizeMessage),
+			"issue_keys":         issueKeys,
+			"diff_files":         changes.Files,
+			"diff_stat":          changes.Stat(),
+		}
+
+		titleLength := 50
+		if len(style.Titles) > 0 {
+			titleLength = style.TitleLength
+		}
+		titleData := util.Data{
+			"summary_points":   summarizeMessage,
+			"history_titles":   style.Titles,
+			"title_max_length": titleLength,
+		}
+		if viper.GetBool("commit.issue_in_title") {
+			titleData["issue_keys"] = issueKeys
+		}
+		titleMessages, err := promptMessages(
+			"commit",
 			prompt.SummarizeTitleTemplate,
-			util.Data{
-				"summary_points": summarizeMessage,
-			},
+			titleData,
 		)
 		if err != nil {
 			return err
 		}
 
-		// Get summarize title from diff datas
-		color.Cyan("We are trying to summarize a title for pull request")
-		resp, err = client.Completion(cmd.Context(), out)
+		// generate the candidate commit messages, one for every title choice
+		generate := func() ([]string, error) {
+			// Get summarize title from diff datas
+			color.Cyan("We are trying to summarize a title for pull request")
+			resp, err := client.Chat(cmd.Context(), titleMessages, viper.GetInt("commit.choices"))
+			if err != nil {
+				return nil, err
+			}
+			color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
+				", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
+				", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
+			)
+
+			messages := []string{}
+			for _, summarizeTitle := range resp.Choices {
+				data["summarize_title"] = formatTitle(summarizeTitle, issueKeys, style.Capitalized)
+				commitMessage, err := renderCommitMessage(data)
+				if err != nil {
+					return nil, err
+				}
+
+				if prompt.GetLanguage(viper.GetString("output.lang")) != prompt.DefaultLanguage {
+					messages, err := promptMessages(
+						"commit",
+						prompt.TranslationTemplate,
+						util.Data{
+							"output_language": prompt.GetLanguage(viper.GetString("output.lang")),
+							"output_message":  commitMessage,
+						},
+					)
+					if err != nil {
+						return nil, err
+					}
+
+					// translate a git commit message
+					color.Cyan("We are trying to translate a git commit message to " + prompt.GetLanguage(viper.GetString("output.lang")) + " language")
+					resp, err := client.Chat(cmd.Context(), messages, 1)
+					if err != nil {
+						return nil, err
+					}
+					color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
+						", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
+						", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
+					)
+					commitMessage = resp.Content
+				}
+				messages = append(messages, commitMessage)
+			}
+			return messages, nil
+		}
+
+		// lint the commit message and re-prompt the model with the violations
+		rules, err := lintRules(g)
 		if err != nil {
-			return err
+			// a config which can't be read shouldn't block the commit
+			color.Yellow("Skip linting the commit message: " + err.Error())
+			rules = nil
 		}
-		summarizeTitle := resp.Content
-		color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
-			", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
-			", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
-		)
+		if dryRun {
+			// the placeholder responses can't satisfy the rules
+			rules = nil
+		}
+		retries := 2
+		if viper.IsSet("lint.retries") {
+			retries = viper.GetInt("lint.retries")
+		}
+		lintMessage := func(commitMessage string) (string, error) {
+			if rules == nil {
+				return commitMessage, nil
+			}
+			for i := 0; ; i++ {
+				violations := lint.Lint(commitMessage, rules)
+				if len(violations) == 0 {
+					return commitMessage, nil
+				}
 
-		// lowercase the first character of first word of the commit message and remove last period
-		summarizeTitle = strings.TrimRight(strings.ToLower(string(summarizeTitle[0]))+summarizeTitle[1:], ".")
+				problems := []string{}
+				for _, v := range violations {
+					color.Red(v.String())
+					problems = append(problems, v.String())
+				}
+				if !lint.HasErrors(violations) || i >= retries {
+					return commitMessage, nil
+				}
 
-		// support conventional commits
-		out, err = util.GetTemplateByString(
-			prompt.ConventionalCommitTemplate,
-			util.Data{
-				"summary_points": summarizeMessage,
-			},
-		)
-		if err != nil {
-			return err
+				out, err := util.GetTemplateByString(
+					prompt.CommitLintTemplate,
+					util.Data{
+						"commit_message": commitMessage,
+						"violations":     problems,
+					},
+				)
+				if err != nil {
+					return "", err
+				}
+				conversation = append(conversation, openai.Message{Role: openai.RoleUser, Content: out})
+
+				color.Cyan("We are trying to fix the commit message violating the commitlint rules")
+				resp, err := client.Chat(cmd.Context(), conversation, 1)
+				if err != nil {
+					return "", err
+				}
+				color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
+					", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
+					", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
+				)
+				commitMessage = strings.TrimSpace(resp.Content)
+				conversation = append(conversation, openai.Message{Role: openai.RoleAssistant, Content: commitMessage})
+			}
 		}
-		color.Cyan("We are trying to get conventional commit prefix")
-		resp, err = client.Completion(cmd.Context(), out)
+
+		candidates, err := generate()
 		if err != nil {
 			return err
 		}
-		summarizePrefix := resp.Content
-		color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
-			", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
-			", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
-		)
+		commitMessage := candidates[0]
 
-		var commitMessage string
-		data := util.Data{
-			"summarize_prefix":  strings.TrimSpace(summarizePrefix),
-			"summarize_title":   strings.TrimSpace(summarizeTitle),
-			"summarize_message": strings.TrimSpace(summarizeMessage),
+		// choose, edit or regenerate the commit message in the terminal. Without --interactive,
+		// the first candidate is used by the hook (--preview) or without a terminal.
+		pick := interactive || len(candidates) > 1 && !preview && hasTerminal()
+		if len(candidates) > 1 && !pick {
+			color.Yellow("Use the first of " + strconv.Itoa(len(candidates)) + " candidates, run with --interactive to choose one")
 		}
-		if viper.GetString("git.template_file") != "" {
-			format, err := os.ReadFile(viper.GetString("git.template_file"))
+		if pick && !dryRun {
+			commitMessage, err = pickMessage(candidates, generate, func(message string) (string, error) {
+				editor, err := g.Editor()
+				if err != nil {
+					return "", err
+				}
+				return editMessage(editor, message)
+			})
 			if err != nil {
 				return err
 			}
-			commitMessage, err = util.NewTemplateByString(
-				string(format),
-				data,
-			)
-			if err != nil {
-				return err
+		}
+
+		commitMessage, err = lintMessage(commitMessage)
+		if err != nil {
+			return err
+		}
+
+		for {
+			// Output commit summary data from AI
+			color.Yellow("================Commit Summary====================")
+			color.Yellow("\n" + strings.TrimSpace(commitMessage) + "\n\n")
+			color.Yellow("==================================================")
+
+			if !interactive || dryRun {
+				break
 			}
-		} else if viper.GetString("git.template_string") != "" {
-			commitMessage, err = util.NewTemplateByString(
-				viper.GetString("git.template_string"),
-				data,
-			)
+
+			// regenerate the commit message with the user feedback, keeping the conversation context
+			feedback, err := readLine("Type feedback to regenerate the message or press enter to accept: ")
 			if err != nil {
 				return err
 			}
-		} else {
-			commitMessage, err = util.GetTemplateByString(
-				git.CommitMessageTemplate,
-				data,
-			)
-			if err != nil {
-				return err
+			if feedback == "" {
+				break
 			}
-		}
 
-		if prompt.GetLanguage(viper.GetString("output.lang")) != prompt.DefaultLanguage {
-			out, err = util.GetTemplateByString(
-				prompt.TranslationTemplate,
+			out, err := util.GetTemplateByString(
+				prompt.CommitFeedbackTemplate,
 				util.Data{
-					"output_language": prompt.GetLanguage(viper.GetString("output.lang")),
-					"output_message":  commitMessage,
+					"commit_message": commitMessage,
+					"feedback":       feedback,
 				},
 			)
 			if err != nil {
 				return err
 			}
+			conversation = append(conversation, openai.Message{Role: openai.RoleUser, Content: out})
 
-			// translate a git commit message
-			color.Cyan("We are trying to translate a git commit message to " + prompt.GetLanguage(viper.GetString("output.lang")) + " language")
-			resp, err := client.Completion(cmd.Context(), out)
+			color.Cyan("We are trying to regenerate the commit message with your feedback")
+			resp, err := client.Chat(cmd.Context(), conversation, 1)
 			if err != nil {
 				return err
 			}
@@ -213,13 +502,18 @@ var commitCmd = &cobra.Command{
 				", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
 				", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
 			)
-			commitMessage = resp.Content
+			commitMessage = strings.TrimSpace(resp.Content)
+			conversation = append(conversation, openai.Message{Role: openai.RoleAssistant, Content: commitMessage})
+
+			commitMessage, err = lintMessage(commitMessage)
+			if err != nil {
+				return err
+			}
 		}
 
-		// Output commit summary data from AI
-		color.Yellow("================Commit Summary====================")
-		color.Yellow("\n" + strings.TrimSpace(commitMessage) + "\n\n")
-		color.Yellow("==================================================")
+		if c, ok := client.(*dryRunClient); ok {
+			return c.report()
+		}
 
 		outputFile := viper.GetString("output.file")
 		if outputFile == "" {
@@ -247,6 +541,61 @@ var commitCmd = &cobra.Command{
 			return err
 		}
 		color.Yellow(output)
+
+		sha, err := g.RevParse("HEAD")
+		if err != nil {
+			return err
+		}
+		color.Green("Commit " + sha + " successfully")
 		return nil
 	},
 }
+
+// formatTitle lowercases the first character of first word of the title and removes last period
+// unless the title starts with a ticket ID or the repository history uses capitalized titles.
+func formatTitle(title string, issueKeys []string, capitalized bool) string {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return title
+	}
+
+	for _, key := range issueKeys {
+		if strings.HasPrefix(title, key) {
+			return strings.TrimRight(title, ".")
+		}
+	}
+
+	if capitalized {
+		title = strings.ToUpper(string(title[0])) + title[1:]
+	} else {
+		title = strings.ToLower(string(title[0])) + title[1:]
+	}
+	return strings.TrimRight(title, ".")
+}
+
+// renderCommitMessage renders the commit message with the template file,
+// the template string or the default template.
+func renderCommitMessage(data util.Data) (string, error) {
+	if viper.GetString("git.template_file") != "" {
+		format, err := os.ReadFile(viper.GetString("git.template_file"))
+		if err != nil {
+			return "", err
+		}
+		return util.NewTemplateByString(
+			string(format),
+			data,
+		)
+	}
+
+	if viper.GetString("git.template_string") != "" {
+		return util.NewTemplateByString(
+			viper.GetString("git.template_string"),
+			data,
+		)
+	}
+
+	return util.GetTemplateByString(
+		git.CommitMessageTemplate,
+		data,
+	)
+}
@@ -16,6 +16,8 @@ var availableKeys = []string{
 	"git.exclude_list",
 	"git.template_file",
 	"git.template_string",
+	"git.backend",
+	"git.include_generated",
 	"openai.socks",
 	"openai.api_key",
 	"openai.model",
@@ -26,6 +28,34 @@ var availableKeys = []string{
 	"openai.timeout",
 	"openai.max_tokens",
 	"openai.temperature",
+	"prompt.folder",
+	"commit.scopes",
+	"commit.issue_pattern",
+	"commit.issue_in_title",
+	"commit.history_size",
+	"commit.style",
+	"commit.gitmoji_model",
+	"commit.choices",
+	"commit.system_prompt",
+	"commit.signoff",
+	"commit.gpg_sign",
+	"commit.verify",
+	"commit.author",
+	"commit.co_authors",
+	"commit.detect_breaking",
+	"review.system_prompt",
+	"review.block_severity",
+	"lint.config",
+	"lint.retries",
+	"lint.suggest",
+	"redact.enabled",
+	"redact.rules",
+	"redact.allowlist",
+	"usage.file",
+	"usage.prices",
+	"budget.daily",
+	"budget.monthly",
+	"budget.fallback_model",
 }
 
 func init() {
@@ -43,6 +73,7 @@ func init() {
 	configCmd.PersistentFlags().IntP("max_tokens", "", 300, "the maximum number of tokens to generate in the chat completion.")
 	configCmd.PersistentFlags().Float32P("temperature", "", 0.7, "What sampling temperature to use, between 0 and 2. Higher values like 0.8 will make the output more random, while lower values like 0.2 will make it more focused and deterministic.")
 	configCmd.PersistentFlags().StringSliceP("exclude_list", "", []string{}, "exclude file from `git diff` command")
+	configCmd.PersistentFlags().StringP("prompt_folder", "", "", "folder of templates which override the built-in prompts")
 
 	_ = viper.BindPFlag("openai.base_url", configCmd.PersistentFlags().Lookup("base_url"))
 	_ = viper.BindPFlag("openai.org_id", configCmd.PersistentFlags().Lookup("org_id"))
@@ -58,6 +89,7 @@ func init() {
 	_ = viper.BindPFlag("git.exclude_list", configCmd.PersistentFlags().Lookup("exclude_list"))
 	_ = viper.BindPFlag("git.template_file", configCmd.PersistentFlags().Lookup("template_file"))
 	_ = viper.BindPFlag("git.template_string", configCmd.PersistentFlags().Lookup("template_string"))
+	_ = viper.BindPFlag("prompt.folder", configCmd.PersistentFlags().Lookup("prompt_folder"))
 }
 
 var configCmd = &cobra.Command{
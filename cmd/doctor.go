@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// diagnosis is the result of a single doctor check.
+type diagnosis struct {
+	name    string
+	passed  bool
+	message string
+}
+
+func (d diagnosis) print() {
+	if d.passed {
+		color.Green("[PASS] %s: %s", d.name, d.message)
+		return
+	}
+	color.Red("[FAIL] %s: %s", d.name, d.message)
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the environment and configuration of codegpt",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		results := []diagnosis{}
+
+		// check git command exist
+		if util.IsCommandAvailable("git") {
+			gitPath, _ := exec.LookPath("git")
+			results = append(results, diagnosis{"git", true, gitPath})
+		} else {
+			results = append(results, diagnosis{"git", false, "git command not found on your system's PATH"})
+		}
+
+		// check config file location and parse errors
+		configFile := viper.ConfigFileUsed()
+		if configFile == "" {
+			configFile = cfgFile
+		}
+		if cfgErr != nil {
+			results = append(results, diagnosis{"config", false, configFile + ": " + cfgErr.Error()})
+		} else {
+			results = append(results, diagnosis{"config", true, configFile})
+		}
+
+		// check model validity
+		model := viper.GetString("openai.model")
+		if openai.IsValidModel(model) {
+			results = append(results, diagnosis{"model", true, model})
+		} else {
+			results = append(results, diagnosis{"model", false, "unsupported model: " + model})
+		}
+
+		// check API reachability through the configured proxy
+		results = append(results, checkAPI(cmd, model))
+
+		// check hook installation state
+		results = append(results, checkHook()...)
+
+		failed := 0
+		for _, r := range results {
+			r.print()
+			if !r.passed {
+				failed++
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d checks failed", failed, len(results))
+		}
+
+		return nil
+	},
+}
+
+func checkAPI(cmd *cobra.Command, model string) diagnosis {
+	via := "direct connection"
+	switch {
+	case viper.GetString("openai.proxy") != "":
+		via = "proxy " + viper.GetString("openai.proxy")
+	case viper.GetString("openai.socks") != "":
+		via = "socks " + viper.GetString("openai.socks")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return diagnosis{"api", false, err.Error()}
+	}
+
+	models, err := client.ListModels(cmd.Context())
+	if err != nil {
+		return diagnosis{"api", false, "can't reach the API using " + via + ": " + err.Error()}
+	}
+
+	for _, m := range models.Models {
+		if m.ID == openai.GetModel(model) {
+			return diagnosis{"api", true, "reachable using " + via + ", model " + model + " is available"}
+		}
+	}
+
+	return diagnosis{"api", false, "reachable using " + via + ", but model " + model + " is not available for this account"}
+}
+
+func checkHook() []diagnosis {
+	g, err := newRepository()
+	if err != nil {
+		return []diagnosis{{"hook", false, err.Error()}}
+	}
+	if _, err := g.TopLevel(); err != nil {
+		return []diagnosis{{"hook", false, "not a git repository"}}
+	}
+
+	results := []diagnosis{}
+
+	installed, err := g.IsHookInstalled(git.HookPrepareCommitMessageTemplate)
+	switch {
+	case err != nil:
+		results = append(results, diagnosis{"hook", false, err.Error()})
+	case installed:
+		results = append(results, diagnosis{"hook", true, git.HookPrepareCommitMessageTemplate + " hook is installed"})
+	default:
+		results = append(results, diagnosis{
+			"hook",
+			false,
+			git.HookPrepareCommitMessageTemplate + " hook is not installed, run `codegpt hook install`",
+		})
+	}
+
+	hooksPath, _ := g.HookPath()
+	manager := git.HookManager(hooksPath)
+	if manager != "" {
+		results = append(results, diagnosis{
+			"hook manager",
+			false,
+			manager + " owns the hooks directory " + hooksPath,
+		})
+	} else {
+		results = append(results, diagnosis{"hook manager", true, "no other hook manager detected"})
+	}
+
+	return results
+}
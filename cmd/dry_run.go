@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/openai"
+
+	"github.com/fatih/color"
+	"github.com/spf13/viper"
+)
+
+var (
+	// render the prompts without calling the model
+	dryRun bool
+	// folder where the dry run writes the prompts instead of printing them
+	dryRunDir string
+)
+
+// chatClient generates the chat completion choices of a conversation.
+type chatClient interface {
+	Chat(ctx context.Context, messages []openai.Message, n int) (*openai.Response, error)
+}
+
+// newChatClient returns the OpenAI client recording the token usage of the command,
+// or the dry run client if --dry-run is set.
+func newChatClient(command string) (chatClient, error) {
+	if dryRun {
+		return &dryRunClient{
+			model:     viper.GetString("openai.model"),
+			maxTokens: viper.GetInt("openai.max_tokens"),
+			dir:       dryRunDir,
+		}, nil
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+	return &usageClient{
+		client:  client,
+		ledger:  ledger(),
+		command: command,
+		model:   viper.GetString("openai.model"),
+		repo:    currentRepo(),
+	}, nil
+}
+
+// dryRunResponse is the content returned by the dry run client instead of the model response,
+// so the next prompts show where the response is used.
+const dryRunResponse = "[dry run response]"
+
+// dryRunClient prints or writes the prompts exactly as they would be sent and estimates
+// their tokens, without any network request.
+type dryRunClient struct {
+	model     string
+	maxTokens int
+	dir       string
+
+	requests         int
+	promptTokens     int
+	completionTokens int
+}
+
+func (c *dryRunClient) Chat(_ context.Context, messages []openai.Message, n int) (*openai.Response, error) {
+	if n < 1 {
+		n = 1
+	}
+	c.requests++
+
+	out := strings.Builder{}
+	for _, m := range messages {
+		fmt.Fprintf(&out, "--- %s ---\n%s\n\n", m.Role, strings.TrimSpace(m.Content))
+	}
+
+	if c.dir != "" {
+		if err := os.MkdirAll(c.dir, 0o755); err != nil {
+			return nil, err
+		}
+		target := filepath.Join(c.dir, fmt.Sprintf("prompt-%02d.txt", c.requests))
+		if err := os.WriteFile(target, []byte(out.String()), 0o644); err != nil {
+			return nil, err
+		}
+		color.Cyan("Write the prompt to " + target + " file")
+	} else {
+		color.White("=================Prompt " + strconv.Itoa(c.requests) + "=========================")
+		color.White(out.String())
+	}
+
+	resp := &openai.Response{}
+	for i := 1; i <= n; i++ {
+		if n == 1 {
+			resp.Choices = append(resp.Choices, dryRunResponse)
+			continue
+		}
+		resp.Choices = append(resp.Choices, "[dry run response "+strconv.Itoa(i)+"]")
+	}
+	resp.Content = resp.Choices[0]
+	// the completion can't be known, max_tokens for every choice is the upper bound
+	resp.Usage.PromptTokens = openai.EstimateTokens(messages)
+	resp.Usage.CompletionTokens = c.maxTokens * n
+	resp.Usage.TotalTokens = resp.Usage.PromptTokens + resp.Usage.CompletionTokens
+	c.promptTokens += resp.Usage.PromptTokens
+	c.completionTokens += resp.Usage.CompletionTokens
+
+	return resp, nil
+}
+
+// report prints the number of requests, the estimated tokens and cost of the dry run.
+func (c *dryRunClient) report() error {
+	color.Green("Dry run: " + strconv.Itoa(c.requests) + " requests, about " +
+		strconv.Itoa(c.promptTokens) + " prompt tokens and up to " +
+		strconv.Itoa(c.completionTokens) + " completion tokens, nothing was sent to " + c.model)
+
+	prices, err := modelPrices()
+	if err != nil {
+		return err
+	}
+	price, ok := prices[c.model]
+	if !ok {
+		color.Yellow("No price of the " + c.model + " model to estimate the cost")
+		return nil
+	}
+	color.Green(fmt.Sprintf("Estimated cost: up to $%.4f", price.Cost(c.promptTokens, c.completionTokens)))
+	return nil
+}
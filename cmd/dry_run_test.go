@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/appleboy/CodeGPT/openai"
+
+	"github.com/fatih/color"
+	"github.com/spf13/viper"
+)
+
+func TestDryRunClient(t *testing.T) {
+	viper.Set("usage.prices", "my-model=1/2")
+	defer viper.Set("usage.prices", nil)
+	output := color.Output
+	defer func() { color.Output = output }()
+	out := &bytes.Buffer{}
+	color.Output = out
+
+	dir := filepath.Join(t.TempDir(), "prompts")
+	client := &dryRunClient{model: "my-model", maxTokens: 100, dir: dir}
+	messages := []openai.Message{
+		{Role: "system", Content: "You are an expert programmer."},
+		{Role: "user", Content: "Summarize the diff\n"},
+	}
+
+	resp, err := client.Chat(context.Background(), messages, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != dryRunResponse {
+		t.Errorf("Chat() content = %q, want %q", resp.Content, dryRunResponse)
+	}
+	resp, err = client.Chat(context.Background(), messages[1:], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"[dry run response 1]", "[dry run response 2]"}; !reflect.DeepEqual(resp.Choices, want) {
+		t.Errorf("Chat() choices = %q, want %q", resp.Choices, want)
+	}
+
+	// the prompts are written exactly as they would be sent, one file per request
+	for name, want := range map[string]string{
+		"prompt-01.txt": "--- system ---\nYou are an expert programmer.\n\n--- user ---\nSummarize the diff\n\n",
+		"prompt-02.txt": "--- user ---\nSummarize the diff\n\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	// max_tokens for every choice is the upper bound of the completion
+	promptTokens := openai.EstimateTokens(messages) + openai.EstimateTokens(messages[1:])
+	completionTokens := 100 + 2*100
+	if client.requests != 2 || client.promptTokens != promptTokens || client.completionTokens != completionTokens {
+		t.Errorf("dryRunClient totals = %d requests, %d prompt and %d completion tokens, want 2, %d and %d",
+			client.requests, client.promptTokens, client.completionTokens, promptTokens, completionTokens)
+	}
+
+	out.Reset()
+	if err := client.report(); err != nil {
+		t.Fatal(err)
+	}
+	cost := float64(promptTokens*1+completionTokens*2) / 1000
+	for _, want := range []string{
+		fmt.Sprintf("Dry run: 2 requests, about %d prompt tokens and up to %d completion tokens", promptTokens, completionTokens),
+		fmt.Sprintf("Estimated cost: up to $%.4f", cost),
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report() = %q, want %q", out.String(), want)
+		}
+	}
+
+	// models without a price have no estimated cost
+	client.model = "unknown-model"
+	out.Reset()
+	if err := client.report(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No price of the unknown-model model") {
+		t.Errorf("report() = %q, want no estimated cost", out.String())
+	}
+}
@@ -2,18 +2,25 @@ package cmd
 
 import (
 	"errors"
+	"path"
+	"strconv"
+	"strings"
 
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/lint"
 	"github.com/appleboy/CodeGPT/openai"
 	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/redact"
 	"github.com/appleboy/CodeGPT/util"
 	"github.com/appleboy/com/file"
 
+	"github.com/fatih/color"
 	"github.com/spf13/viper"
 )
 
 func check() error {
 	// check git command exist
-	if !util.IsCommandAvailable("git") {
+	if !util.IsCommandAvailable("git") && viper.GetString("git.backend") == git.BackendExec {
 		return errors.New("Git command not found on your system's PATH. Please install Git and try again.")
 	}
 
@@ -61,5 +68,185 @@ func check() error {
 		}
 	}
 
+	return loadPromptFolders()
+}
+
+// newClient returns the OpenAI client using the openai.* config.
+func newClient() (*openai.Client, error) {
+	return openai.New(
+		openai.WithToken(viper.GetString("openai.api_key")),
+		openai.WithModel(viper.GetString("openai.model")),
+		openai.WithOrgID(viper.GetString("openai.org_id")),
+		openai.WithProxyURL(viper.GetString("openai.proxy")),
+		openai.WithSocksURL(viper.GetString("openai.socks")),
+		openai.WithBaseURL(viper.GetString("openai.base_url")),
+		openai.WithTimeout(viper.GetDuration("openai.timeout")),
+		openai.WithMaxTokens(viper.GetInt("openai.max_tokens")),
+		openai.WithTemperature(float32(viper.GetFloat64("openai.temperature"))),
+	)
+}
+
+// newRepository returns the git backend selected by the git.backend config. By default, it uses
+// the git command and falls back to go-git if git is not installed.
+func newRepository(opts ...git.Option) (git.Repository, error) {
+	backend := viper.GetString("git.backend")
+	if backend == "" {
+		backend = git.BackendExec
+		if !util.IsCommandAvailable("git") {
+			backend = git.BackendGoGit
+		}
+	}
+
+	switch backend {
+	case git.BackendExec:
+		return git.New(opts...), nil
+	case git.BackendGoGit:
+		return git.OpenGoGit(".", opts...)
+	}
+
+	return nil, errors.New("git.backend must be " + git.BackendExec + " or " + git.BackendGoGit)
+}
+
+// redactDiff masks the secrets of the diff with the built-in and redact.rules rules before
+// it is sent to the model, and reports what was masked. It is enabled unless redact.enabled is false.
+func redactDiff(diff string) (string, error) {
+	if viper.IsSet("redact.enabled") && !viper.GetBool("redact.enabled") {
+		return diff, nil
+	}
+
+	rules, err := redact.ParseRules(listConfig("redact.rules"))
+	if err != nil {
+		return "", err
+	}
+	r, err := redact.New(append(redact.DefaultRules(), rules...), listConfig("redact.allowlist"))
+	if err != nil {
+		return "", err
+	}
+
+	diff, findings := r.Diff(diff)
+	if len(findings) > 0 {
+		color.Yellow("Redacted " + strconv.Itoa(len(findings)) + " secrets before sending the diff to the model:")
+		for _, f := range findings {
+			color.Yellow("  " + f.String())
+		}
+	}
+
+	return diff, nil
+}
+
+// promptMessages renders the prompt template into chat messages: the system prompt configured
+// for the command (e.g. commit.system_prompt), the system block defined by the template and the user content.
+func promptMessages(command, name string, data util.Data) ([]openai.Message, error) {
+	messages := []openai.Message{}
+	if viper.GetString(command+".system_prompt") != "" {
+		messages = append(messages, openai.Message{
+			Role:    openai.RoleSystem,
+			Content: viper.GetString(command + ".system_prompt"),
+		})
+	}
+
+	system, err := util.GetSystemTemplateByString(name, data)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(system) != "" {
+		messages = append(messages, openai.Message{
+			Role:    openai.RoleSystem,
+			Content: strings.TrimSpace(system),
+		})
+	}
+
+	content, err := util.GetTemplateByString(name, data)
+	if err != nil {
+		return nil, err
+	}
+	messages = append(messages, openai.Message{
+		Role:    openai.RoleUser,
+		Content: content,
+	})
+
+	return messages, nil
+}
+
+// lintRules returns the commitlint rules from the lint.config file or the commitlint config
+// found in the repository top-level folder. It returns nil if there is no config.
+func lintRules(g git.Repository) (lint.Rules, error) {
+	target := viper.GetString("lint.config")
+	if target == "" {
+		out, err := g.TopLevel()
+		if err != nil {
+			return nil, err
+		}
+		target = lint.FindConfig(strings.TrimSpace(out))
+	}
+	if target == "" {
+		return nil, nil
+	}
+
+	return lint.LoadConfig(target)
+}
+
+// listConfig returns the list config value. A string value set by `codegpt config set`
+// is split by commas, so the items can contain spaces like `A U Thor <author@example.com>`.
+// Commas inside brackets or braces are kept, like the `{8,32}` quantifier of a regex.
+func listConfig(key string) []string {
+	val, ok := viper.Get(key).(string)
+	if !ok {
+		return viper.GetStringSlice(key)
+	}
+
+	items, depth, start := []string{}, 0, 0
+	add := func(item string) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	for i, r := range val {
+		switch {
+		case r == '[' || r == '{':
+			depth++
+		case (r == ']' || r == '}') && depth > 0:
+			depth--
+		case r == ',' && depth == 0:
+			add(val[start:i])
+			start = i + 1
+		}
+	}
+	add(val[start:])
+	return items
+}
+
+// promptFolders returns the folders whose templates override the embedded templates.
+// The repository folder .codegpt/prompts takes precedence over the prompt.folder config.
+func promptFolders() []string {
+	folders := []string{}
+	if viper.GetString("prompt.folder") != "" {
+		folders = append(folders, viper.GetString("prompt.folder"))
+	}
+
+	if g, err := newRepository(); err == nil {
+		if out, err := g.TopLevel(); err == nil {
+			folders = append(folders, path.Join(strings.TrimSpace(out), ".codegpt", "prompts"))
+		}
+	}
+
+	return folders
+}
+
+// loadPromptFolders overrides the embedded templates by name with the files found in the prompt folders.
+func loadPromptFolders() error {
+	for _, folder := range promptFolders() {
+		if !file.IsDir(folder) {
+			continue
+		}
+		_, unknown, err := util.LoadTemplatesFromDir(folder)
+		if err != nil {
+			return err
+		}
+		for _, name := range unknown {
+			color.Yellow("Skip " + path.Join(folder, name) + ", no built-in template has this name, see `codegpt prompt list`")
+		}
+	}
+
 	return nil
 }
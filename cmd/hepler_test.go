@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestListConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		val  interface{}
+		want []string
+	}{
+		{"unset", nil, nil},
+		{"yaml list", []interface{}{"openai/=openai", "cmd/=cli"}, []string{"openai/=openai", "cmd/=cli"}},
+		{"prices", "gpt-4=0.03/0.06,my-model=0.001", []string{"gpt-4=0.03/0.06", "my-model=0.001"}},
+		{"single", "openai/=openai", []string{"openai/=openai"}},
+		{"commas", "openai/=openai, cmd/=cli,", []string{"openai/=openai", "cmd/=cli"}},
+		{"spaces", "A U Thor <author@example.com>,Foo <foo@example.com>", []string{"A U Thor <author@example.com>", "Foo <foo@example.com>"}},
+		{"regex", "token=tk_[a-z,]{8,32},^EXAMPLE$", []string{"token=tk_[a-z,]{8,32}", "^EXAMPLE$"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("test.list", tt.val)
+			defer viper.Set("test.list", nil)
+			if got := listConfig("test.list"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("listConfig() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,38 +2,188 @@ package cmd
 
 import (
 	"errors"
+	"os"
+	"path"
+	"strings"
 
 	"github.com/appleboy/CodeGPT/git"
 
+	"github.com/appleboy/com/array"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+var hookType string
+
+func init() {
+	hookCmd.Flags().StringVar(&hookType, "type", git.HookPrepareCommitMessageTemplate,
+		"git hook type: "+strings.Join(git.Hooks, ", "))
+}
+
 var hookCmd = &cobra.Command{
 	Use:   "hook",
-	Short: "install/uninstall git prepare-commit-msg hook",
-	Args:  cobra.MinimumNArgs(1),
+	Short: "install/uninstall git hooks (prepare-commit-msg by default)",
+	Long: "install/uninstall git hooks (prepare-commit-msg by default).\n\n" +
+		"The installed hooks call `codegpt hook run <hook> [args...]` with the arguments passed by git.",
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] == "run" {
+			if len(args) < 2 {
+				return errors.New("hook run <hook> [args...]. ex: hook run " + git.HookPrepareCommitMessageTemplate + " .git/COMMIT_EDITMSG")
+			}
+			return runHook(cmd, args[1], args[2:])
+		}
+
 		if args[0] != "install" && args[0] != "uninstall" {
-			return errors.New("only support install or uninstall command")
+			return errors.New("only support install, uninstall or run command")
 		}
 
-		g := git.New()
+		if !array.InSlice(hookType, git.Hooks) {
+			return errors.New("only support " + strings.Join(git.Hooks, ", ") + " hook type")
+		}
+
+		g, err := newRepository()
+		if err != nil {
+			return err
+		}
 
 		switch args[0] {
 		case "install":
-			if err := g.InstallHook(); err != nil {
+			if err := g.InstallHook(hookType); err != nil {
 				return err
 			}
-			color.Green("Install git hook: prepare-commit-msg successfully")
-			color.Green("You can see the hook file: .git/hooks/prepare-commit-msg")
+			hookPath, err := g.HookPath()
+			if err != nil {
+				return err
+			}
+			color.Green("Install git hook: " + hookType + " successfully")
+			color.Green("You can see the hook file: " + path.Join(hookPath, hookType))
 		case "uninstall":
-			if err := g.UninstallHook(); err != nil {
+			if err := g.UninstallHook(hookType); err != nil {
 				return err
 			}
-			color.Green("Remove git hook: prepare-commit-msg successfully")
+			color.Green("Remove git hook: " + hookType + " successfully")
 		}
 
 		return nil
 	},
 }
+
+// runHook runs the git hook with the arguments passed by git.
+func runHook(cmd *cobra.Command, name string, args []string) error {
+	switch name {
+	case git.HookPrepareCommitMessageTemplate:
+		return runPrepareCommitMessage(cmd, args)
+	case git.HookCommitMessageTemplate:
+		if len(args) < 1 {
+			return errors.New("missing the commit message file")
+		}
+		return lintMessageCmd.RunE(cmd, args[:1])
+	case git.HookPrePushTemplate:
+		reviewPrePush = true
+		return reviewCmd.RunE(cmd, nil)
+	}
+
+	return errors.New("only support " + strings.Join(git.Hooks, ", ") + " hook type")
+}
+
+// runPrepareCommitMessage writes the generated commit message to the file prepared by git.
+// git passes the source of the message (message, template, merge, squash or commit) and the
+// commit SHA for the commit source. Messages given by the user with -m, merges and squashes are
+// kept, and --amend summarizes the changes of HEAD together with the staged changes.
+// The comment lines of the file, like the status or the diff of --verbose, are preserved.
+func runPrepareCommitMessage(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return errors.New("missing the commit message file")
+	}
+	messageFile, source, sha := args[0], "", ""
+	if len(args) > 1 {
+		source = args[1]
+	}
+	if len(args) > 2 {
+		sha = args[2]
+	}
+
+	content, err := os.ReadFile(messageFile)
+	if err != nil {
+		return err
+	}
+	message, comments := splitComments(string(content))
+
+	isHead := false
+	if source == "commit" {
+		// -c and -C reuse the message of another commit, --amend reuses HEAD
+		g, err := newRepository()
+		if err != nil {
+			return err
+		}
+		head, err := g.RevParse("HEAD")
+		if err != nil {
+			return err
+		}
+		commit, err := g.RevParse(sha)
+		isHead = err == nil && commit == head
+	}
+
+	generate, amend := prepareCommitMessage(source, message, isHead)
+	if !generate {
+		return nil
+	}
+	commitAmend = amend
+
+	viper.Set("output.file", messageFile)
+	preview = true
+	if err := commitCmd.RunE(cmd, nil); err != nil {
+		return err
+	}
+
+	if comments == "" {
+		return nil
+	}
+
+	content, err = os.ReadFile(messageFile)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(messageFile, []byte(joinComments(string(content), comments)), 0o644)
+}
+
+// prepareCommitMessage decides whether the message is generated for the source passed by git
+// and the message already in the file, and whether the changes of HEAD are summarized too.
+// isHead reports whether the commit source reuses the message of HEAD, which is --amend.
+func prepareCommitMessage(source, message string, isHead bool) (generate, amend bool) {
+	switch source {
+	case "message", "merge", "squash":
+		return false, false
+	case "template":
+		// keep the template if it has more than comments for the user to fill in
+		return strings.TrimSpace(message) == "", false
+	case "commit":
+		return isHead, isHead
+	}
+
+	return true, false
+}
+
+// joinComments appends the comment lines split from the file prepared by git to the generated message.
+func joinComments(message, comments string) string {
+	if comments == "" {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\n" + comments
+}
+
+// splitComments splits the commit message file prepared by git into the message and the
+// trailing comment lines, which include everything below the scissors line of --verbose.
+func splitComments(content string) (string, string) {
+	lines := strings.SplitAfter(content, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			return strings.Join(lines[:i], ""), strings.Join(lines[i:], "")
+		}
+	}
+
+	return content, ""
+}
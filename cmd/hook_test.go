@@ -0,0 +1,70 @@
+package cmd
+
+import "testing"
+
+func TestPrepareCommitMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		source       string
+		message      string
+		isHead       bool
+		wantGenerate bool
+		wantAmend    bool
+	}{
+		{"no source", "", "", false, true, false},
+		{"message", "message", "fix: typo\n", false, false, false},
+		{"merge", "merge", "Merge branch 'main'\n", false, false, false},
+		{"squash", "squash", "Squashed commit of the following:\n", false, false, false},
+		{"empty template", "template", "\n", false, true, false},
+		{"filled template", "template", "feat: \n\nRefs: #\n", false, false, false},
+		{"commit HEAD", "commit", "feat: add cache\n", true, true, true},
+		{"commit other SHA", "commit", "feat: add cache\n", false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generate, amend := prepareCommitMessage(tt.source, tt.message, tt.isHead)
+			if generate != tt.wantGenerate || amend != tt.wantAmend {
+				t.Errorf("prepareCommitMessage() = %v, %v, want %v, %v", generate, amend, tt.wantGenerate, tt.wantAmend)
+			}
+		})
+	}
+}
+
+func TestSplitComments(t *testing.T) {
+	status := "# Please enter the commit message for your changes.\n#\n# Changes to be committed:\n#\tmodified:   main.go\n"
+	verbose := status + "# ------------------------ >8 ------------------------\n" +
+		"# Do not modify or remove the line above.\n" +
+		"diff --git a/main.go b/main.go\n" +
+		"-#!/usr/bin/env go\n" +
+		"+// main is the entry point\n"
+
+	tests := []struct {
+		name         string
+		content      string
+		wantMessage  string
+		wantComments string
+	}{
+		{"no comments", "feat: add cache\n", "feat: add cache\n", ""},
+		{"status", "\n" + status, "\n", status},
+		{"template", "feat: \n\n" + status, "feat: \n\n", status},
+		// the diff below the scissors line is kept even if its lines don't start with #
+		{"scissors", "\n" + verbose, "\n", verbose},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, comments := splitComments(tt.content)
+			if message != tt.wantMessage || comments != tt.wantComments {
+				t.Errorf("splitComments() = %q, %q, want %q, %q", message, comments, tt.wantMessage, tt.wantComments)
+			}
+
+			// the comments are preserved below the generated message
+			want := "feat: add cache\n\n" + tt.wantComments
+			if tt.wantComments == "" {
+				want = "feat: add cache\n"
+			}
+			if got := joinComments("feat: add cache\n", comments); got != want {
+				t.Errorf("joinComments() = %q, want %q", got, want)
+			}
+		})
+	}
+}
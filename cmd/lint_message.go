@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/lint"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// suggest a fixed commit message using the model
+var lintSuggest bool
+
+func init() {
+	lintMessageCmd.Flags().BoolVar(&lintSuggest, "suggest", false, "ask the model to suggest a commit message fixing the violations")
+	lintMessageCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+}
+
+var lintMessageCmd = &cobra.Command{
+	Use:   "lint-message <file>",
+	Short: "lint the commit message file against the commitlint rules",
+	Long: "lint the commit message file against the commitlint rules.\n\n" +
+		"The rules are loaded from the lint.config file or the commitlint config in the repository,\n" +
+		"and default to @commitlint/config-conventional.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		commitMessage := string(content)
+
+		g, err := newRepository()
+		if err != nil {
+			return err
+		}
+		rules, err := lintRules(g)
+		if err != nil {
+			return err
+		}
+		if rules == nil {
+			rules = lint.DefaultRules()
+		}
+
+		violations := lint.Lint(commitMessage, rules)
+		if len(violations) == 0 {
+			return nil
+		}
+
+		problems := []string{}
+		for _, v := range violations {
+			if v.Level == lint.Error {
+				color.Red(v.String())
+			} else {
+				color.Yellow(v.String())
+			}
+			problems = append(problems, v.String())
+		}
+
+		if !lint.HasErrors(violations) {
+			return nil
+		}
+
+		if lintSuggest || viper.GetBool("lint.suggest") {
+			client, err := newChatClient("lint-message")
+			if err != nil {
+				return err
+			}
+
+			messages, err := promptMessages(
+				"commit",
+				prompt.CommitLintTemplate,
+				util.Data{
+					"commit_message": strings.TrimSpace(commitMessage),
+					"violations":     problems,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			color.Cyan("We are trying to suggest a commit message fixing the violations")
+			resp, err := client.Chat(cmd.Context(), messages, 1)
+			if err != nil {
+				return err
+			}
+			color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
+				", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
+				", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
+			)
+
+			color.Yellow("================Suggested Message=================")
+			color.Yellow("\n" + strings.TrimSpace(resp.Content) + "\n\n")
+			color.Yellow("==================================================")
+		}
+
+		return errors.New("commit message violates " + strconv.Itoa(len(violations)) + " commitlint rules")
+	},
+}
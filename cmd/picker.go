@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// errAborted is returned when the user aborts the commit in the interactive picker.
+var errAborted = errors.New("commit aborted")
+
+// terminal returns the input of the interactive terminal.
+// Git hooks run without stdin, so fall back to the controlling terminal, the console input on Windows.
+func terminal() (io.ReadCloser, error) {
+	if isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	name := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		name = "CONIN$"
+	}
+	tty, err := os.Open(name)
+	if err != nil {
+		return nil, errors.New("interactive mode requires a terminal")
+	}
+	return tty, nil
+}
+
+// hasTerminal reports whether the user can answer in a terminal, which editors, GUIs and CI don't have.
+func hasTerminal() bool {
+	tty, err := terminal()
+	if err != nil {
+		return false
+	}
+	tty.Close()
+	return true
+}
+
+// editMessage opens the message in the editor and returns the edited message.
+func editMessage(editor, message string) (string, error) {
+	f, err := os.CreateTemp("", "codegpt-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(message); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	tty, err := terminal()
+	if err != nil {
+		return "", err
+	}
+	defer tty.Close()
+
+	cmd := editorCommand(runtime.GOOS, editor, f.Name())
+	cmd.Stdin = tty
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+// editorCommand returns the command opening the file in the editor, which may contain
+// arguments like `code --wait`. Windows has no sh, so the editor is split and run directly.
+func editorCommand(goos, editor, file string) *exec.Cmd {
+	if goos != "windows" {
+		return exec.Command("sh", "-c", editor+` "$@"`, editor, file)
+	}
+
+	args := splitArgs(editor)
+	if len(args) == 0 {
+		args = []string{"notepad"}
+	}
+	return exec.Command(args[0], append(args[1:], file)...)
+}
+
+// splitArgs splits the command line on spaces outside of double quotes,
+// keeping the backslashes of Windows paths like `"C:\Program Files\Vim\vim.exe" -f`.
+func splitArgs(line string) []string {
+	args := []string{}
+	arg, quoted, started := strings.Builder{}, false, false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted, started = !quoted, true
+		case (r == ' ' || r == '\t') && !quoted:
+			if started {
+				args = append(args, arg.String())
+				arg.Reset()
+				started = false
+			}
+		default:
+			arg.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+// readLine prints the question and reads a line from the terminal.
+func readLine(question string) (string, error) {
+	tty, err := terminal()
+	if err != nil {
+		return "", err
+	}
+	defer tty.Close()
+
+	fmt.Print(question)
+	line, err := bufio.NewReader(tty).ReadString('\n')
+	if err != nil && line == "" {
+		return "", nil
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// pickMessage shows the candidate messages and lets the user choose one, edit it in the editor,
+// regenerate the candidates or abort.
+func pickMessage(
+	candidates []string,
+	regenerate func() ([]string, error),
+	edit func(string) (string, error),
+) (string, error) {
+	tty, err := terminal()
+	if err != nil {
+		return "", err
+	}
+	defer tty.Close()
+	reader := bufio.NewReader(tty)
+
+	for {
+		color.Yellow("================Commit Candidates=================")
+		for i, candidate := range candidates {
+			color.Yellow("\n[%d] %s\n", i+1, strings.TrimSpace(candidate))
+		}
+		color.Yellow("\n==================================================")
+		fmt.Printf("Choose a message [1-%d], (e)dit [n], (r)egenerate or (a)bort: ", len(candidates))
+
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return "", errAborted
+		}
+		answer := strings.ToLower(strings.TrimSpace(line))
+
+		switch {
+		case answer == "a" || answer == "abort":
+			return "", errAborted
+		case answer == "r" || answer == "regenerate":
+			candidates, err = regenerate()
+			if err != nil {
+				return "", err
+			}
+		case strings.HasPrefix(answer, "e"):
+			index := 1
+			if n := strings.TrimSpace(strings.TrimLeft(answer, "edit")); n != "" {
+				index, err = strconv.Atoi(n)
+				if err != nil || index < 1 || index > len(candidates) {
+					color.Red("Invalid choice: %s", answer)
+					continue
+				}
+			}
+			message, err := edit(candidates[index-1])
+			if err != nil {
+				return "", err
+			}
+			if message == "" {
+				return "", errAborted
+			}
+			return message, nil
+		default:
+			index, err := strconv.Atoi(answer)
+			if err != nil || index < 1 || index > len(candidates) {
+				color.Red("Invalid choice: %s", answer)
+				continue
+			}
+			return candidates[index-1], nil
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		goos   string
+		editor string
+		want   []string
+	}{
+		{"linux", "vim", []string{"sh", "-c", `vim "$@"`, "vim", "msg.txt"}},
+		{"linux", "code --wait", []string{"sh", "-c", `code --wait "$@"`, "code --wait", "msg.txt"}},
+		{"windows", "notepad", []string{"notepad", "msg.txt"}},
+		{"windows", "code --wait", []string{"code", "--wait", "msg.txt"}},
+		{"windows", `"C:\Program Files\Vim\vim.exe"  -f`, []string{`C:\Program Files\Vim\vim.exe`, "-f", "msg.txt"}},
+		{"windows", "", []string{"notepad", "msg.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goos+" "+tt.editor, func(t *testing.T) {
+			if got := editorCommand(tt.goos, tt.editor, "msg.txt").Args; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("editorCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
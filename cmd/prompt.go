@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/prompt"
+
+	"github.com/appleboy/com/array"
+	"github.com/appleboy/com/file"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var promptForce bool
+
+func init() {
+	promptCmd.Flags().BoolVar(&promptForce, "force", false, "overwrite existing files when exporting templates")
+}
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "list/show/export the built-in prompt templates",
+	Long: "list/show/export the built-in prompt templates.\n\n" +
+		"Files in the prompt.folder config or the repository .codegpt/prompts folder\n" +
+		"override the built-in templates with the same name.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := prompt.Templates()
+		if err != nil {
+			return err
+		}
+
+		switch args[0] {
+		case "list":
+			for _, name := range names {
+				source := "built-in"
+				for _, folder := range promptFolders() {
+					if file.IsFile(path.Join(folder, name)) {
+						source = path.Join(folder, name)
+					}
+				}
+				fmt.Printf("%-30s %s\n", name, source)
+			}
+		case "show":
+			if len(args) < 2 {
+				return errors.New("prompt show <name>. ex: prompt show " + prompt.SummarizeTitleTemplate)
+			}
+			if !array.InSlice(args[1], names) {
+				return errors.New("template not found: " + args[1])
+			}
+			content, err := prompt.GetRawTemplate(args[1])
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(content))
+		case "export":
+			folder := ""
+			if len(args) > 1 {
+				folder = args[1]
+			} else {
+				// the repository folder is loaded from the top level, not the current folder
+				g, err := newRepository()
+				if err != nil {
+					return err
+				}
+				out, err := g.TopLevel()
+				if err != nil {
+					return errors.New("not a git repository, pass the folder to export to: prompt export <folder>")
+				}
+				folder = path.Join(strings.TrimSpace(out), ".codegpt", "prompts")
+			}
+			if err := os.MkdirAll(folder, os.ModePerm); err != nil {
+				return err
+			}
+			for _, name := range names {
+				target := path.Join(folder, name)
+				if file.IsFile(target) && !promptForce {
+					color.Yellow("Skip existing template file: %s", target)
+					continue
+				}
+				content, err := prompt.GetRawTemplate(name)
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(target, content, 0o644); err != nil {
+					return err
+				}
+			}
+			color.Green("Export the built-in prompt templates to %s folder", folder)
+		default:
+			return errors.New("only support list, show or export command")
+		}
+
+		return nil
+	},
+}
@@ -1,14 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/appleboy/CodeGPT/git"
-	"github.com/appleboy/CodeGPT/openai"
 	"github.com/appleboy/CodeGPT/prompt"
 	"github.com/appleboy/CodeGPT/util"
 
+	"github.com/appleboy/com/array"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -18,6 +21,9 @@ import (
 // The total length of input tokens and generated tokens is limited by the model's context length.
 var maxTokens int
 
+// review the commits pushed by git, reading the ref ranges from stdin like the pre-push hook
+var reviewPrePush bool
+
 func init() {
 	reviewCmd.Flags().IntVar(&diffUnified, "diff_unified", 3, "generate diffs with <n> lines of context, default is 3")
 	reviewCmd.Flags().IntVar(&maxTokens, "max_tokens", 300, "the maximum number of tokens to generate in the chat completion.")
@@ -25,6 +31,9 @@ func init() {
 	reviewCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
 	reviewCmd.Flags().StringSliceVar(&excludeList, "exclude_list", []string{}, "exclude file from git diff command")
 	reviewCmd.Flags().BoolVar(&commitAmend, "amend", false, "replace the tip of the current branch by creating a new commit.")
+	reviewCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the prompts and estimate the tokens and cost without calling the model")
+	reviewCmd.Flags().StringVar(&dryRunDir, "dry-run-dir", "", "write the prompts of the dry run to the folder")
+	reviewCmd.Flags().BoolVar(&reviewPrePush, "pre-push", false, "review the pushed ref ranges read from stdin and fail on high severity issues")
 }
 
 var reviewCmd = &cobra.Command{
@@ -35,84 +44,198 @@ var reviewCmd = &cobra.Command{
 			return err
 		}
 
-		g := git.New(
+		if reviewPrePush {
+			return reviewPush(cmd.Context())
+		}
+
+		g, err := newRepository(
 			git.WithDiffUnified(viper.GetInt("git.diff_unified")),
 			git.WithExcludeList(viper.GetStringSlice("git.exclude_list")),
+			git.WithIncludeGenerated(viper.GetBool("git.include_generated")),
 			git.WithEnableAmend(commitAmend),
 		)
+		if err != nil {
+			return err
+		}
 		diff, err := g.DiffFiles()
 		if err != nil {
 			return err
 		}
 
 		color.Green("Code review your changes using " + viper.GetString("openai.model") + " model")
-		client, err := openai.New(
-			openai.WithToken(viper.GetString("openai.api_key")),
-			openai.WithModel(viper.GetString("openai.model")),
-			openai.WithOrgID(viper.GetString("openai.org_id")),
-			openai.WithProxyURL(viper.GetString("openai.proxy")),
-			openai.WithSocksURL(viper.GetString("openai.socks")),
-			openai.WithBaseURL(viper.GetString("openai.base_url")),
-			openai.WithTimeout(viper.GetDuration("openai.timeout")),
-			openai.WithMaxTokens(viper.GetInt("openai.max_tokens")),
-			openai.WithTemperature(float32(viper.GetFloat64("openai.temperature"))),
-		)
+		client, err := newChatClient("review")
 		if err != nil {
 			return err
 		}
 
-		out, err := util.GetTemplateByString(
-			prompt.CodeReviewTemplate,
+		summarizeMessage, _, err := reviewDiff(cmd.Context(), client, diff, false)
+		if err != nil {
+			return err
+		}
+
+		// Output core review summary
+		color.Yellow("================Review Summary====================")
+		color.Yellow("\n" + strings.TrimSpace(summarizeMessage) + "\n\n")
+		color.Yellow("==================================================")
+
+		if c, ok := client.(*dryRunClient); ok {
+			return c.report()
+		}
+
+		return nil
+	},
+}
+
+// reviewDiff asks the model to review the diff and translates the review to the output language.
+// If reportSeverity is set, the model reports the severity of the most serious issue,
+// which is returned apart from the review.
+func reviewDiff(ctx context.Context, client chatClient, diff string, reportSeverity bool) (string, string, error) {
+	diff, err := redactDiff(diff)
+	if err != nil {
+		return "", "", err
+	}
+	changes, err := git.ParseDiff(diff)
+	if err != nil {
+		return "", "", err
+	}
+
+	messages, err := promptMessages(
+		"review",
+		prompt.CodeReviewTemplate,
+		util.Data{
+			"file_diffs":      diff,
+			"diff_files":      changes.Files,
+			"diff_stat":       changes.Stat(),
+			"report_severity": reportSeverity,
+		},
+	)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Get summarize comment from diff datas
+	color.Cyan("We are trying to review code changes")
+	resp, err := client.Chat(ctx, messages, 1)
+	if err != nil {
+		return "", "", err
+	}
+	color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
+		", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
+		", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
+	)
+	severity, summarizeMessage := "", resp.Content
+	if reportSeverity {
+		severity, summarizeMessage = prompt.ParseSeverity(summarizeMessage)
+	}
+
+	if prompt.GetLanguage(viper.GetString("output.lang")) != prompt.DefaultLanguage {
+		messages, err = promptMessages(
+			"review",
+			prompt.TranslationTemplate,
 			util.Data{
-				"file_diffs": diff,
+				"output_language": prompt.GetLanguage(viper.GetString("output.lang")),
+				"output_message":  summarizeMessage,
 			},
 		)
 		if err != nil {
-			return err
+			return "", "", err
 		}
 
-		// Get summarize comment from diff datas
-		color.Cyan("We are trying to review code changes")
-		resp, err := client.Completion(cmd.Context(), out)
+		// translate a git commit message
+		color.Cyan("We are trying to translate code review to " + prompt.GetLanguage(viper.GetString("output.lang")) + " language")
+		resp, err := client.Chat(ctx, messages, 1)
 		if err != nil {
-			return err
+			return "", "", err
 		}
-		summarizeMessage := resp.Content
 		color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
 			", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
 			", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
 		)
+		summarizeMessage = resp.Content
+	}
 
-		if prompt.GetLanguage(viper.GetString("output.lang")) != prompt.DefaultLanguage {
-			out, err = util.GetTemplateByString(
-				prompt.TranslationTemplate,
-				util.Data{
-					"output_language": prompt.GetLanguage(viper.GetString("output.lang")),
-					"output_message":  summarizeMessage,
-				},
-			)
-			if err != nil {
-				return err
-			}
+	return summarizeMessage, severity, nil
+}
 
-			// translate a git commit message
-			color.Cyan("We are trying to translate code review to " + prompt.GetLanguage(viper.GetString("output.lang")) + " language")
-			resp, err := client.Completion(cmd.Context(), out)
+// reviewPush reviews each ref range pushed by git and returns an error if any review reports
+// issues at or above the review.block_severity level, so the pre-push hook blocks the push.
+// The hook is run by git, so the pushed ranges are diffed with the git binary whatever git.backend is.
+func reviewPush(ctx context.Context) error {
+	ranges, err := git.ParsePushRanges(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	threshold := strings.ToLower(viper.GetString("review.block_severity"))
+	if threshold == "" {
+		threshold = "high"
+	}
+	if !array.InSlice(threshold, prompt.Severities) {
+		return errors.New("review.block_severity must be one of " + strings.Join(prompt.Severities, ", "))
+	}
+
+	var client chatClient
+	blocked := []string{}
+	for _, r := range ranges {
+		base, err := git.New().PushBase(r)
+		if err != nil {
+			return err
+		}
+		if base == "" {
+			continue
+		}
+
+		g := git.New(
+			git.WithDiffUnified(viper.GetInt("git.diff_unified")),
+			git.WithExcludeList(viper.GetStringSlice("git.exclude_list")),
+			git.WithIncludeGenerated(viper.GetBool("git.include_generated")),
+			git.WithDiffRange(base, r.LocalSHA),
+		)
+		names, err := g.DiffNames()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			continue
+		}
+		diff, err := g.DiffFiles()
+		if err != nil {
+			return err
+		}
+
+		if client == nil {
+			client, err = newChatClient("review")
 			if err != nil {
 				return err
 			}
-			color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
-				", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
-				", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
-			)
-			summarizeMessage = resp.Content
 		}
 
-		// Output core review summary
+		color.Green("Code review " + r.LocalRef + " using " + viper.GetString("openai.model") + " model")
+		summarizeMessage, severity, err := reviewDiff(ctx, client, diff, true)
+		if err != nil {
+			return err
+		}
+
 		color.Yellow("================Review Summary====================")
 		color.Yellow("\n" + strings.TrimSpace(summarizeMessage) + "\n\n")
+		if severity != "" {
+			color.Yellow("Severity: " + severity)
+		}
 		color.Yellow("==================================================")
 
-		return nil
-	},
+		if prompt.SeverityAtLeast(severity, threshold) {
+			blocked = append(blocked, r.LocalRef)
+		}
+	}
+
+	if c, ok := client.(*dryRunClient); ok {
+		return c.report()
+	}
+
+	if len(blocked) > 0 {
+		return errors.New("push blocked by " + threshold + " severity issues in " + strings.Join(blocked, ", ") +
+			", fix them or use `git push --no-verify` to skip the review")
+	}
+
+	return nil
 }
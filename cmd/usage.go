@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/usage"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage [daily|monthly]",
+	Short: "Show the token usage and estimated cost by day or month",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		period := usage.Daily
+		if len(args) > 0 {
+			period = args[0]
+		}
+		if period != usage.Daily && period != usage.Monthly {
+			return errors.New("usage period must be " + usage.Daily + " or " + usage.Monthly)
+		}
+
+		prices, err := modelPrices()
+		if err != nil {
+			return err
+		}
+		l := ledger()
+		records, err := l.Records()
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			color.Yellow("No usage recorded in " + l.Path() + " yet")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PERIOD\tMODEL\tREQUESTS\tPROMPT\tCOMPLETION\tTOTAL\tCOST")
+		requests, tokens, cost, unknown := 0, 0, 0.0, false
+		for _, t := range usage.Totals(records, period, prices) {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%s\n",
+				t.Period, t.Model, t.Requests, t.PromptTokens, t.CompletionTokens, t.TotalTokens, formatCost(t.Cost, t.Unknown))
+			requests += t.Requests
+			tokens += t.TotalTokens
+			cost += t.Cost
+			unknown = unknown || t.Unknown
+		}
+		fmt.Fprintf(w, "TOTAL\t\t%d\t\t\t%d\t%s\n", requests, tokens, formatCost(cost, unknown))
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if unknown {
+			color.Yellow("Some models have no price, add them with `codegpt config set usage.prices model=prompt/completion`")
+		}
+		return nil
+	},
+}
+
+// formatCost formats the estimated cost in USD, marking the totals missing the cost of some models.
+func formatCost(cost float64, unknown bool) string {
+	if unknown {
+		return fmt.Sprintf("$%.4f+", cost)
+	}
+	return fmt.Sprintf("$%.4f", cost)
+}
+
+// ledger returns the usage ledger, by default usage.jsonl next to the config file.
+func ledger() *usage.Ledger {
+	if viper.GetString("usage.file") != "" {
+		return usage.NewLedger(viper.GetString("usage.file"))
+	}
+	// the config file created on the first run isn't read yet, so viper doesn't know it
+	configFile := viper.ConfigFileUsed()
+	if configFile == "" {
+		configFile = cfgFile
+	}
+	return usage.NewLedger(filepath.Join(filepath.Dir(configFile), "usage.jsonl"))
+}
+
+// modelPrices returns the built-in prices of the models overridden by the usage.prices config.
+func modelPrices() (map[string]openai.Price, error) {
+	custom, err := openai.ParsePrices(listConfig("usage.prices"))
+	if err != nil {
+		return nil, err
+	}
+
+	prices := map[string]openai.Price{}
+	for model, price := range openai.Prices {
+		prices[model] = price
+	}
+	for model, price := range custom {
+		prices[model] = price
+	}
+	return prices, nil
+}
+
+// usageClient records the token usage of every call in the ledger.
+type usageClient struct {
+	client  chatClient
+	ledger  *usage.Ledger
+	command string
+	model   string
+	repo    string
+}
+
+func (c *usageClient) Chat(ctx context.Context, messages []openai.Message, n int) (*openai.Response, error) {
+	if err := c.checkBudget(messages, n); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Chat(ctx, messages, n)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.ledger.Append(usage.Record{
+		Time:             time.Now(),
+		Command:          c.command,
+		Model:            c.model,
+		Repo:             c.repo,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		TotalTokens:      resp.Usage.TotalTokens,
+	}); err != nil {
+		color.Yellow("Can't record the token usage: " + err.Error())
+	}
+
+	return resp, nil
+}
+
+// checkBudget refuses the call if it could exceed the budget.daily or budget.monthly limit,
+// unless the budget.fallback_model fits in the budget, which is then used for the rest of the command.
+func (c *usageClient) checkBudget(messages []openai.Message, n int) error {
+	daily, err := usage.ParseLimit(viper.GetString("budget.daily"))
+	if err != nil {
+		return err
+	}
+	monthly, err := usage.ParseLimit(viper.GetString("budget.monthly"))
+	if err != nil {
+		return err
+	}
+	budget := usage.Budget{Daily: daily, Monthly: monthly}
+	if daily.IsZero() && monthly.IsZero() {
+		return nil
+	}
+
+	prices, err := modelPrices()
+	if err != nil {
+		return err
+	}
+	records, err := c.ledger.Records()
+	if err != nil {
+		return err
+	}
+	if n < 1 {
+		n = 1
+	}
+	// the completion can't be known before the call, max_tokens for every choice is the upper bound
+	next := usage.Record{
+		Time:             time.Now(),
+		Model:            c.model,
+		PromptTokens:     openai.EstimateTokens(messages),
+		CompletionTokens: viper.GetInt("openai.max_tokens") * n,
+	}
+
+	err = budget.Check(records, next, prices)
+	fallback := viper.GetString("budget.fallback_model")
+	if err == nil || fallback == "" || fallback == c.model {
+		return err
+	}
+	if !openai.IsValidModel(fallback) {
+		return errors.New("budget.fallback_model " + fallback + " is not a supported model")
+	}
+
+	next.Model = fallback
+	if fallbackErr := budget.Check(records, next, prices); fallbackErr != nil {
+		return err
+	}
+	color.Yellow(err.Error() + ", falling back to the " + fallback + " model")
+	viper.Set("openai.model", fallback)
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	c.client, c.model = client, fallback
+
+	return nil
+}
+
+// currentRepo returns the top-level folder of the repository, or an empty string outside of a repository.
+func currentRepo() string {
+	g, err := newRepository()
+	if err != nil {
+		return ""
+	}
+	out, err := g.TopLevel()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
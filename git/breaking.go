@@ -0,0 +1,95 @@
+package git
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	goFuncDecl  = regexp.MustCompile(`^func\s+(?:\(\s*\w*\s*\*?\s*(\w+)(?:\[[^\]]*\])?\s*\)\s*)?([A-Z]\w*)\s*[\[(]`)
+	goTypeDecl  = regexp.MustCompile(`^type\s+([A-Z]\w*)\b`)
+	goValueDecl = regexp.MustCompile(`^(?:var|const)\s+([A-Z]\w*)\b`)
+	goPackage   = regexp.MustCompile(`^[ +-]package\s+(\w+)`)
+)
+
+// exportedIdentifier returns the exported Go identifier declared by the line,
+// using `Type.Method` for methods, or an empty string.
+func exportedIdentifier(line string) string {
+	if m := goFuncDecl.FindStringSubmatch(line); m != nil {
+		if m[1] != "" {
+			return m[1] + "." + m[2]
+		}
+		return m[2]
+	}
+	if m := goTypeDecl.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+	if m := goValueDecl.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// isPublicGoFile reports whether the exported identifiers of the file can be used by other modules,
+// skipping the test files and the internal packages. The name is the `b/` path of the diff header.
+func isPublicGoFile(name string) bool {
+	return strings.HasSuffix(name, ".go") &&
+		!strings.HasSuffix(name, "_test.go") &&
+		!strings.Contains(name, "/internal/")
+}
+
+// RemovedExportedIdentifiers returns the exported Go identifiers which are
+// declared in the removed lines of the diff and not declared again in the added lines.
+// Test files, internal packages and the files of package main, when the package clause
+// is in the diff, are ignored.
+func RemovedExportedIdentifiers(diff string) []string {
+	removed := map[string]bool{}
+	added := map[string]bool{}
+	isGoFile := false
+	fileRemoved := []string{}
+	isMain := false
+
+	// keep the identifiers of the file until its end, to drop them if it's in package main
+	flush := func() {
+		if !isMain {
+			for _, id := range fileRemoved {
+				removed[id] = true
+			}
+		}
+		fileRemoved, isMain = []string{}, false
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			isGoFile = isPublicGoFile(line[strings.LastIndex(line, " ")+1:])
+		case !isGoFile,
+			strings.HasPrefix(line, "--- "),
+			strings.HasPrefix(line, "+++ "):
+			continue
+		case goPackage.MatchString(line):
+			isMain = goPackage.FindStringSubmatch(line)[1] == "main"
+		case strings.HasPrefix(line, "-"):
+			if id := exportedIdentifier(line[1:]); id != "" {
+				fileRemoved = append(fileRemoved, id)
+			}
+		case strings.HasPrefix(line, "+"):
+			if id := exportedIdentifier(line[1:]); id != "" {
+				added[id] = true
+			}
+		}
+	}
+	flush()
+
+	ids := []string{}
+	for id := range removed {
+		if !added[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	return ids
+}
@@ -0,0 +1,46 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRemovedExportedIdentifiers(t *testing.T) {
+	diff := `diff --git a/openai/openai.go b/openai/openai.go
+index aadf691..bfef603 100644
+--- a/openai/openai.go
++++ b/openai/openai.go
+@@ -1,10 +1,8 @@
+-func GetModel(model string) string {
++func GetModel(model string, fallback string) string {
+-func (c *Client) Completion(
+-type Response struct {
+-var DefaultModel = openai.GPT3Dot5Turbo
+-func helper() {
++func (c *Client) Chat(
+diff --git a/openai/openai_test.go b/openai/openai_test.go
+--- a/openai/openai_test.go
++++ b/openai/openai_test.go
+-func TestGetModel(t *testing.T) {
+diff --git a/internal/cache/cache.go b/internal/cache/cache.go
+--- a/internal/cache/cache.go
++++ b/internal/cache/cache.go
+-func NewCache() *Cache {
+diff --git a/cmd/codegpt/main.go b/cmd/codegpt/main.go
+--- a/cmd/codegpt/main.go
++++ b/cmd/codegpt/main.go
+@@ -1,5 +1,4 @@
+ package main
+ 
+-func Execute() {
+diff --git a/README.md b/README.md
+--- a/README.md
++++ b/README.md
+-type Foo struct {
+`
+
+	want := []string{"Client.Completion", "DefaultModel", "Response"}
+	if got := RemovedExportedIdentifiers(diff); !reflect.DeepEqual(got, want) {
+		t.Errorf("RemovedExportedIdentifiers() = %v, want %v", got, want)
+	}
+}
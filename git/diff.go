@@ -0,0 +1,299 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FileStatus is the kind of change of a file in the diff.
+type FileStatus string
+
+const (
+	StatusAdded    FileStatus = "added"
+	StatusDeleted  FileStatus = "deleted"
+	StatusModified FileStatus = "modified"
+	StatusRenamed  FileStatus = "renamed"
+	StatusCopied   FileStatus = "copied"
+)
+
+// LineType is the kind of a line in a hunk.
+type LineType string
+
+const (
+	LineContext LineType = "context"
+	LineAdded   LineType = "added"
+	LineDeleted LineType = "deleted"
+)
+
+// Line is a line of a hunk with its line numbers in the old and new file.
+// OldNumber is 0 for added lines and NewNumber is 0 for deleted lines.
+type Line struct {
+	Type      LineType
+	Content   string
+	OldNumber int
+	NewNumber int
+}
+
+// Hunk is a block of changes starting with a header like `@@ -1,3 +1,4 @@ func main() {`.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	// Section is the function context git prints after the header, if any.
+	Section string
+	Lines   []Line
+}
+
+// FileDiff is the diff of a file.
+type FileDiff struct {
+	OldPath   string
+	NewPath   string
+	OldMode   string
+	NewMode   string
+	Status    FileStatus
+	Binary    bool
+	Hunks     []Hunk
+	Additions int
+	Deletions int
+	// Skipped is why the content was replaced with a placeholder, like `generated`, or empty.
+	Skipped string
+	// Patch is the raw diff of the file, starting with the `diff --git` line.
+	Patch string
+}
+
+// Path returns the path of the file after the change, or before it if the file is deleted.
+func (f *FileDiff) Path() string {
+	if f.Status == StatusDeleted {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// Diff is a parsed unified diff.
+type Diff struct {
+	Files []*FileDiff
+}
+
+// Additions returns the number of added lines of all files.
+func (d *Diff) Additions() int {
+	n := 0
+	for _, f := range d.Files {
+		n += f.Additions
+	}
+	return n
+}
+
+// Deletions returns the number of deleted lines of all files.
+func (d *Diff) Deletions() int {
+	n := 0
+	for _, f := range d.Files {
+		n += f.Deletions
+	}
+	return n
+}
+
+// Stat returns the summary line like `git diff --shortstat`.
+func (d *Diff) Stat() string {
+	plural := func(n int, one, many string) string {
+		if n == 1 {
+			return one
+		}
+		return many
+	}
+
+	return fmt.Sprintf("%d %s changed, %d %s(+), %d %s(-)",
+		len(d.Files), plural(len(d.Files), "file", "files"),
+		d.Additions(), plural(d.Additions(), "insertion", "insertions"),
+		d.Deletions(), plural(d.Deletions(), "deletion", "deletions"),
+	)
+}
+
+var (
+	hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+	// placeholder is the line replacing the content of the files left out of the prompt
+	placeholder = regexp.MustCompile(`^\[(\w+) file \w+(?:, \+(\d+) -(\d+) lines)?, content omitted\]$`)
+)
+
+// unquote removes the quotes git adds to paths with special characters.
+func unquote(val string) string {
+	// git appends a tab to the ---/+++ paths containing spaces
+	val = strings.TrimRight(val, "\t")
+	if strings.HasPrefix(val, `"`) {
+		if unquoted, err := strconv.Unquote(val); err == nil {
+			return unquoted
+		}
+	}
+	return val
+}
+
+// unquotePath removes the quotes and the a/ or b/ prefix of the path.
+func unquotePath(val string) string {
+	val = unquote(val)
+	if val == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(val, "a/") || strings.HasPrefix(val, "b/") {
+		return val[2:]
+	}
+	return val
+}
+
+// headerPaths returns the old and new paths of the `diff --git a/<old> b/<new>` line.
+func headerPaths(val string) (string, string) {
+	if strings.HasPrefix(val, `"`) {
+		// quoted paths can't be split at a space
+		if end := strings.Index(val[1:], `" `); end >= 0 {
+			return unquotePath(val[:end+2]), unquotePath(val[end+3:])
+		}
+	}
+
+	// both paths are the same unless the file is renamed
+	if n := (len(val) - 5) / 2; n > 0 && len(val) == 2*n+5 && val[2+n:5+n] == " b/" && val[2:2+n] == val[5+n:] {
+		return val[2 : 2+n], val[5+n:]
+	}
+
+	if i := strings.LastIndex(val, " b/"); i >= 0 {
+		return unquotePath(val[:i]), unquotePath(val[i+1:])
+	}
+	return unquotePath(val), unquotePath(val)
+}
+
+// ParseDiff parses the output of `git diff` into files and hunks.
+func ParseDiff(val string) (*Diff, error) {
+	d := &Diff{Files: []*FileDiff{}}
+	var current *FileDiff
+	var hunk *Hunk
+	var patch []string
+	oldRemaining, newRemaining := 0, 0
+	oldNumber, newNumber := 0, 0
+
+	finishHunk := func() {
+		if hunk != nil && current != nil {
+			current.Hunks = append(current.Hunks, *hunk)
+		}
+		hunk = nil
+	}
+	finishFile := func() {
+		finishHunk()
+		if current != nil {
+			current.Patch = strings.Join(patch, "\n") + "\n"
+			d.Files = append(d.Files, current)
+		}
+		current = nil
+		patch = nil
+	}
+
+	lines := strings.Split(strings.TrimSuffix(val, "\n"), "\n")
+	for _, line := range lines {
+		// content lines of the hunk, counted with the header so `--- ` lines are not headers
+		if hunk != nil && (oldRemaining > 0 || newRemaining > 0) {
+			patch = append(patch, line)
+			content := ""
+			if len(line) > 0 {
+				content = line[1:]
+			}
+			switch {
+			case strings.HasPrefix(line, "+"):
+				hunk.Lines = append(hunk.Lines, Line{Type: LineAdded, Content: content, NewNumber: newNumber})
+				current.Additions++
+				newNumber++
+				newRemaining--
+			case strings.HasPrefix(line, "-"):
+				hunk.Lines = append(hunk.Lines, Line{Type: LineDeleted, Content: content, OldNumber: oldNumber})
+				current.Deletions++
+				oldNumber++
+				oldRemaining--
+			case strings.HasPrefix(line, `\`):
+				// \ No newline at end of file
+			default:
+				hunk.Lines = append(hunk.Lines, Line{Type: LineContext, Content: content, OldNumber: oldNumber, NewNumber: newNumber})
+				oldNumber++
+				newNumber++
+				oldRemaining--
+				newRemaining--
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "diff --git ") {
+			finishFile()
+			current = &FileDiff{Status: StatusModified}
+			current.OldPath, current.NewPath = headerPaths(strings.TrimPrefix(line, "diff --git "))
+			patch = []string{line}
+			continue
+		}
+
+		if current == nil {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			return nil, fmt.Errorf("invalid diff line: %q", line)
+		}
+		patch = append(patch, line)
+
+		switch {
+		case strings.HasPrefix(line, "@@ "):
+			finishHunk()
+			match := hunkHeader.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("invalid hunk header: %q", line)
+			}
+			hunk = &Hunk{OldLines: 1, NewLines: 1, Section: match[5], Lines: []Line{}}
+			hunk.OldStart, _ = strconv.Atoi(match[1])
+			hunk.NewStart, _ = strconv.Atoi(match[3])
+			if match[2] != "" {
+				hunk.OldLines, _ = strconv.Atoi(match[2])
+			}
+			if match[4] != "" {
+				hunk.NewLines, _ = strconv.Atoi(match[4])
+			}
+			oldRemaining, newRemaining = hunk.OldLines, hunk.NewLines
+			oldNumber, newNumber = hunk.OldStart, hunk.NewStart
+		case strings.HasPrefix(line, `\`):
+			// \ No newline at end of file
+		case strings.HasPrefix(line, "new file mode "):
+			current.Status = StatusAdded
+			current.NewMode = strings.TrimPrefix(line, "new file mode ")
+		case strings.HasPrefix(line, "deleted file mode "):
+			current.Status = StatusDeleted
+			current.OldMode = strings.TrimPrefix(line, "deleted file mode ")
+		case strings.HasPrefix(line, "old mode "):
+			current.OldMode = strings.TrimPrefix(line, "old mode ")
+		case strings.HasPrefix(line, "new mode "):
+			current.NewMode = strings.TrimPrefix(line, "new mode ")
+		case strings.HasPrefix(line, "rename from "):
+			current.Status = StatusRenamed
+			current.OldPath = unquote(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "rename to "):
+			current.Status = StatusRenamed
+			current.NewPath = unquote(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "copy from "):
+			current.Status = StatusCopied
+			current.OldPath = unquote(strings.TrimPrefix(line, "copy from "))
+		case strings.HasPrefix(line, "copy to "):
+			current.Status = StatusCopied
+			current.NewPath = unquote(strings.TrimPrefix(line, "copy to "))
+		case strings.HasPrefix(line, "Binary files "), strings.HasPrefix(line, "GIT binary patch"):
+			current.Binary = true
+		case placeholder.MatchString(line):
+			match := placeholder.FindStringSubmatch(line)
+			current.Skipped = match[1]
+			current.Additions, _ = strconv.Atoi(match[2])
+			current.Deletions, _ = strconv.Atoi(match[3])
+		case strings.HasPrefix(line, "--- "):
+			if p := unquotePath(strings.TrimPrefix(line, "--- ")); p != "" {
+				current.OldPath = p
+			}
+		case strings.HasPrefix(line, "+++ "):
+			if p := unquotePath(strings.TrimPrefix(line, "+++ ")); p != "" {
+				current.NewPath = p
+			}
+		}
+	}
+	finishFile()
+
+	return d, nil
+}
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sampleDiff = `diff --git a/bin.dat b/bin.dat
+index bdc955b..8835708 100644
+Binary files a/bin.dat and b/bin.dat differ
+diff --git a/del.txt b/del.txt
+deleted file mode 100644
+index 3367afd..0000000
+--- a/del.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-old
+diff --git a/m.txt b/m.txt
+index de98044..9d57350 100644
+--- a/m.txt
++++ b/m.txt
+@@ -1,3 +1,4 @@
+ a
+-b
++B
+ c
++-- x
+diff --git a/ren.txt b/renamed.txt
+similarity index 85%
+rename from ren.txt
+rename to renamed.txt
+index b2f931a..b566061 100644
+--- a/ren.txt
++++ b/renamed.txt
+@@ -3,3 +3,4 @@ two
+ three
+ four
+ five
++six
+diff --git a/sp ace.txt b/sp ace.txt
+new file mode 100644
+index 0000000..3e75765
+--- /dev/null
++++ b/sp ace.txt` + "\t" + `
+@@ -0,0 +1 @@
++new
+`
+
+func TestParseDiff(t *testing.T) {
+	d, err := ParseDiff(sampleDiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type summary struct {
+		OldPath, NewPath, Path string
+		Status                 FileStatus
+		Binary                 bool
+		Additions, Deletions   int
+		Hunks                  int
+	}
+	got := []summary{}
+	for _, f := range d.Files {
+		got = append(got, summary{f.OldPath, f.NewPath, f.Path(), f.Status, f.Binary, f.Additions, f.Deletions, len(f.Hunks)})
+	}
+	want := []summary{
+		{"bin.dat", "bin.dat", "bin.dat", StatusModified, true, 0, 0, 0},
+		{"del.txt", "del.txt", "del.txt", StatusDeleted, false, 0, 1, 1},
+		{"m.txt", "m.txt", "m.txt", StatusModified, false, 2, 1, 1},
+		{"ren.txt", "renamed.txt", "renamed.txt", StatusRenamed, false, 1, 0, 1},
+		{"sp ace.txt", "sp ace.txt", "sp ace.txt", StatusAdded, false, 1, 0, 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDiff() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	hunk := d.Files[2].Hunks[0]
+	wantLines := []Line{
+		{Type: LineContext, Content: "a", OldNumber: 1, NewNumber: 1},
+		{Type: LineDeleted, Content: "b", OldNumber: 2},
+		{Type: LineAdded, Content: "B", NewNumber: 2},
+		{Type: LineContext, Content: "c", OldNumber: 3, NewNumber: 3},
+		{Type: LineAdded, Content: "-- x", NewNumber: 4},
+	}
+	if !reflect.DeepEqual(hunk.Lines, wantLines) {
+		t.Errorf("Hunk.Lines = %+v, want %+v", hunk.Lines, wantLines)
+	}
+	if section := d.Files[3].Hunks[0].Section; section != "two" {
+		t.Errorf("Hunk.Section = %q, want %q", section, "two")
+	}
+
+	if stat := d.Stat(); stat != "5 files changed, 4 insertions(+), 2 deletions(-)" {
+		t.Errorf("Stat() = %q", stat)
+	}
+	if d.Files[4].Patch[:len("diff --git a/sp ace.txt")] != "diff --git a/sp ace.txt" {
+		t.Errorf("Patch = %q", d.Files[4].Patch)
+	}
+}
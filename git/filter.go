@@ -0,0 +1,175 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+)
+
+// Reasons why the content of a changed file is left out of the prompt.
+const (
+	SkipBinary    = "binary"
+	SkipGenerated = "generated"
+	SkipVendored  = "vendored"
+	SkipMinified  = "minified"
+)
+
+var (
+	// vendoredDirs are the folders of third-party code.
+	vendoredDirs = []string{"vendor", "node_modules"}
+	// minifiedSuffixes are the extensions of minified assets and their source maps.
+	minifiedSuffixes = []string{".min.js", ".min.mjs", ".min.css", ".js.map", ".css.map"}
+	// generatedHeader matches the header of generated files, see https://go.dev/s/generatedcode
+	generatedHeader = regexp.MustCompile(`Code generated .*DO NOT EDIT`)
+)
+
+// generatedHeaderLines is how many lines at the top of the file may hold the generated header.
+const generatedHeaderLines = 5
+
+// attributes returns the gitattributes matcher of the root folder and the folders of the changed files.
+func attributes(fs billy.Filesystem, d *Diff) gitattributes.Matcher {
+	dirs := map[string]bool{}
+	for _, f := range d.Files {
+		for dir := path.Dir(f.Path()); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+	// parent folders first, the patterns of nested folders have a higher priority
+	sorted := []string{}
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return strings.Count(sorted[i], "/") < strings.Count(sorted[j], "/") ||
+			(strings.Count(sorted[i], "/") == strings.Count(sorted[j], "/") && sorted[i] < sorted[j])
+	})
+
+	// the root .gitattributes may define macros
+	stack, _ := gitattributes.ReadAttributesFile(fs, nil, ".gitattributes", true)
+	for _, dir := range sorted {
+		patterns, err := gitattributes.ReadAttributesFile(fs, strings.Split(dir, "/"), ".gitattributes", false)
+		if err == nil {
+			stack = append(stack, patterns...)
+		}
+	}
+
+	return gitattributes.NewMatcher(stack)
+}
+
+// isGenerated reports whether the top of the file has a `Code generated ... DO NOT EDIT` header,
+// looking at the diff first and the working tree file otherwise.
+func isGenerated(fs billy.Filesystem, f *FileDiff) bool {
+	for _, h := range f.Hunks {
+		for _, l := range h.Lines {
+			if l.Type != LineDeleted && l.NewNumber <= generatedHeaderLines && generatedHeader.MatchString(l.Content) {
+				return true
+			}
+		}
+	}
+	if f.Status == StatusDeleted {
+		return false
+	}
+
+	file, err := fs.Open(f.Path())
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	buf := make([]byte, 1024)
+	n, _ := io.ReadFull(file, buf)
+	lines := strings.SplitN(string(buf[:n]), "\n", generatedHeaderLines+1)
+	if len(lines) > generatedHeaderLines {
+		lines = lines[:generatedHeaderLines]
+	}
+	for _, line := range lines {
+		if generatedHeader.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipReason returns why the content of the file is left out of the prompt,
+// or an empty string if it is kept.
+func skipReason(fs billy.Filesystem, matcher gitattributes.Matcher, f *FileDiff) string {
+	if f.Binary {
+		return SkipBinary
+	}
+
+	attrs, _ := matcher.Match(strings.Split(f.Path(), "/"), nil)
+	isSet := func(name string) bool {
+		attr, ok := attrs[name]
+		return ok && (attr.IsSet() || (attr.IsValueSet() && attr.Value() == "true"))
+	}
+	if attr, ok := attrs["diff"]; ok && attr.IsUnset() {
+		return SkipBinary
+	}
+	if isSet("binary") {
+		return SkipBinary
+	}
+	if isSet("linguist-generated") {
+		return SkipGenerated
+	}
+	if isSet("linguist-vendored") {
+		return SkipVendored
+	}
+
+	for _, dir := range vendoredDirs {
+		if strings.HasPrefix(f.Path(), dir+"/") || strings.Contains(f.Path(), "/"+dir+"/") {
+			return SkipVendored
+		}
+	}
+	for _, suffix := range minifiedSuffixes {
+		if strings.HasSuffix(f.Path(), suffix) {
+			return SkipMinified
+		}
+	}
+	if isGenerated(fs, f) {
+		return SkipGenerated
+	}
+
+	return ""
+}
+
+// filterDiff replaces the content of binary, generated, vendored and minified files with a
+// one-line placeholder after the file headers, so the model still knows they changed without
+// reading them. ParseDiff reads the line counts and the reason back from the placeholder.
+// The working tree filesystem is used to read .gitattributes and file headers.
+func filterDiff(diff string, fs billy.Filesystem) (string, error) {
+	d, err := ParseDiff(diff)
+	if err != nil {
+		return "", err
+	}
+	matcher := attributes(fs, d)
+
+	out := strings.Builder{}
+	for _, f := range d.Files {
+		reason := skipReason(fs, matcher, f)
+		if reason == "" {
+			out.WriteString(f.Patch)
+			continue
+		}
+
+		// keep the extended headers, so the status, paths and modes can still be parsed
+		for _, line := range strings.Split(strings.TrimSuffix(f.Patch, "\n"), "\n") {
+			if strings.HasPrefix(line, "@@ ") || strings.HasPrefix(line, "GIT binary patch") {
+				break
+			}
+			out.WriteString(line + "\n")
+		}
+		if f.Binary {
+			fmt.Fprintf(&out, "[%s file %s, content omitted]\n", reason, f.Status)
+		} else {
+			fmt.Fprintf(&out, "[%s file %s, +%d -%d lines, content omitted]\n", reason, f.Status, f.Additions, f.Deletions)
+		}
+	}
+
+	return out.String(), nil
+}
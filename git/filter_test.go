@@ -0,0 +1,68 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterDiff(t *testing.T) {
+	repo, wt, fs := testRepository(t)
+
+	writeFile(t, fs, ".gitattributes", "*.pb.go linguist-generated\n")
+	writeFile(t, fs, "assets/.gitattributes", "*.svg -diff\n")
+	writeFile(t, fs, "assets/logo.svg", "<svg></svg>\n")
+	writeFile(t, fs, "api/user.pb.go", "package api\n")
+	writeFile(t, fs, "mock/user.go", "// Code generated by MockGen. DO NOT EDIT.\npackage mock\n")
+	writeFile(t, fs, "vendor/github.com/foo/foo.go", "package foo\n")
+	writeFile(t, fs, "web/app.min.js", "var a=1;\n")
+	writeFile(t, fs, "logo.png", "\x89PNG\x00\x01")
+	writeFile(t, fs, "main.go", "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n")
+	if _, err := wt.Add("."); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := NewGoGit(repo).DiffFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"+++ b/api/user.pb.go\n[generated file added, +1 -0 lines, content omitted]\n",
+		"+++ b/assets/logo.svg\n[binary file added, +1 -0 lines, content omitted]\n",
+		"[binary file added, content omitted]\n",
+		"+++ b/mock/user.go\n[generated file added, +2 -0 lines, content omitted]\n",
+		"+++ b/vendor/github.com/foo/foo.go\n[vendored file added, +1 -0 lines, content omitted]\n",
+		"+++ b/web/app.min.js\n[minified file added, +1 -0 lines, content omitted]\n",
+		"+\tprintln(\"hello\")",
+		"+*.pb.go linguist-generated",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("DiffFiles() missing %q in\n%s", want, diff)
+		}
+	}
+
+	// the placeholders keep the status and line counts of the skipped files
+	d, err := ParseDiff(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range d.Files {
+		switch f.Path() {
+		case "mock/user.go":
+			if f.Status != StatusAdded || f.Skipped != SkipGenerated || f.Additions != 2 {
+				t.Errorf("ParseDiff() %s = %s %q +%d, want added generated +2", f.Path(), f.Status, f.Skipped, f.Additions)
+			}
+		case "logo.png":
+			if f.Status != StatusAdded || !f.Binary || f.Skipped != SkipBinary {
+				t.Errorf("ParseDiff() %s = %s binary %v %q, want added binary", f.Path(), f.Status, f.Binary, f.Skipped)
+			}
+		}
+	}
+
+	diff, err = NewGoGit(repo, WithIncludeGenerated(true)).DiffFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "+package foo") {
+		t.Errorf("DiffFiles() should include vendored file with WithIncludeGenerated\n%s", diff)
+	}
+}
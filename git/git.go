@@ -1,16 +1,14 @@
 package git
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
-	"os"
 	"os/exec"
-	"path"
 	"strconv"
 	"strings"
 
-	"github.com/appleboy/CodeGPT/util"
-	"github.com/appleboy/com/file"
+	"github.com/go-git/go-billy/v5/osfs"
 )
 
 var excludeFromDiff = []string{
@@ -26,6 +24,19 @@ type Command struct {
 	diffUnified int
 	excludeList []string
 	isAmend     bool
+	// compare the diffFrom and diffTo commits instead of the staged changes
+	diffFrom string
+	diffTo   string
+	// commit options
+	signoff   bool
+	gpgSign   string
+	verify    bool
+	author    string
+	coAuthors []string
+	// keep the content of binary, generated and vendored files in the diff
+	includeGenerated bool
+	// files matching the .codegptignore patterns
+	ignoreList []string
 }
 
 func (c *Command) excludeFiles() []string {
@@ -33,21 +44,47 @@ func (c *Command) excludeFiles() []string {
 	for _, f := range c.excludeList {
 		newFileLists = append(newFileLists, ":(exclude)"+f)
 	}
+	for _, f := range c.ignoreList {
+		newFileLists = append(newFileLists, ":(top,literal,exclude)"+f)
+	}
 
 	return newFileLists
 }
 
+func (c *Command) diffRange() []string {
+	switch {
+	case c.diffTo != "":
+		return []string{c.diffFrom, c.diffTo}
+	case c.isAmend:
+		// the amended commit includes the changes of HEAD and the staged changes
+		if _, err := c.RevParse("HEAD^"); err != nil {
+			// HEAD is the root commit
+			return []string{"--staged", EmptyTreeSHA}
+		}
+		return []string{"--staged", "HEAD^"}
+	}
+
+	return []string{"--staged"}
+}
+
+// diffFormat keeps the output of git diff parseable whatever the user config is,
+// like color.ui=always, diff.external, diff.noprefix or diff.mnemonicPrefix.
+var diffFormat = []string{
+	"--no-color",
+	"--no-ext-diff",
+	"--src-prefix=a/",
+	"--dst-prefix=b/",
+}
+
 func (c *Command) diffNames() *exec.Cmd {
 	args := []string{
 		"diff",
 		"--name-only",
 	}
 
-	if c.isAmend {
-		args = append(args, "HEAD^", "HEAD")
-	} else {
-		args = append(args, "--staged")
-	}
+	args = append(args, diffFormat...)
+
+	args = append(args, c.diffRange()...)
 
 	args = append(args, c.excludeFiles()...)
 
@@ -65,11 +102,9 @@ func (c *Command) diffFiles() *exec.Cmd {
 		"--unified=" + strconv.Itoa(c.diffUnified),
 	}
 
-	if c.isAmend {
-		args = append(args, "HEAD^", "HEAD")
-	} else {
-		args = append(args, "--staged")
-	}
+	args = append(args, diffFormat...)
+
+	args = append(args, c.diffRange()...)
 
 	args = append(args, c.excludeFiles()...)
 
@@ -104,14 +139,91 @@ func (c *Command) topLevel() *exec.Cmd {
 	)
 }
 
+func (c *Command) currentBranch() *exec.Cmd {
+	args := []string{
+		"branch",
+		"--show-current",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+func (c *Command) log(n int) *exec.Cmd {
+	args := []string{
+		"log",
+		"--no-merges",
+		"--max-count=" + strconv.Itoa(n),
+		"--format=%B%x00",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+func (c *Command) editor() *exec.Cmd {
+	args := []string{
+		"var",
+		"GIT_EDITOR",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+func (c *Command) revParse(rev string) *exec.Cmd {
+	args := []string{
+		"rev-parse",
+		"--verify",
+		"--quiet",
+		rev,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
 func (c *Command) commit(val string) *exec.Cmd {
 	args := []string{
 		"commit",
-		"--no-verify",
-		"--signoff",
-		fmt.Sprintf("--message=%s", val),
 	}
 
+	if !c.verify {
+		args = append(args, "--no-verify")
+	}
+
+	if c.signoff {
+		args = append(args, "--signoff")
+	}
+
+	switch c.gpgSign {
+	case "":
+	case "true":
+		args = append(args, "--gpg-sign")
+	case "false":
+		args = append(args, "--no-gpg-sign")
+	default:
+		args = append(args, "--gpg-sign="+c.gpgSign)
+	}
+
+	if c.author != "" {
+		args = append(args, "--author="+c.author)
+	}
+
+	for _, coAuthor := range c.coAuthors {
+		args = append(args, "--trailer=Co-authored-by: "+coAuthor)
+	}
+
+	args = append(args, fmt.Sprintf("--message=%s", val))
+
 	if c.isAmend {
 		args = append(args, "--amend")
 	}
@@ -122,10 +234,20 @@ func (c *Command) commit(val string) *exec.Cmd {
 	)
 }
 
+// Commit records the staged changes with the commit message and returns the output of git.
+// If git fails, the error includes the message git printed, e.g. the output of a failing hook.
 func (c *Command) Commit(val string) (string, error) {
-	output, err := c.commit(val).Output()
+	var stderr bytes.Buffer
+	cmd := c.commit(val)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
 	if err != nil {
-		return "", err
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			// e.g. `nothing to commit` is printed to stdout
+			message = strings.TrimSpace(string(output))
+		}
+		return "", fmt.Errorf("git commit failed: %w\n%s", err, message)
 	}
 
 	return string(output), nil
@@ -142,10 +264,71 @@ func (c *Command) TopLevel() (string, error) {
 	return string(output), nil
 }
 
+// CurrentBranch returns the name of the current branch.
+// In detached HEAD state, it returns an empty string.
+func (c *Command) CurrentBranch() (string, error) {
+	output, err := c.currentBranch().Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Log returns the last n commit messages of the current branch, excluding merge commits.
+func (c *Command) Log(n int) ([]string, error) {
+	output, err := c.log(n).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []string{}
+	for _, message := range strings.Split(string(output), "\x00") {
+		if message = strings.TrimSpace(message); message != "" {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages, nil
+}
+
+// Editor returns the editor configured for git, honoring
+// $GIT_EDITOR, core.editor, $VISUAL and $EDITOR in that order.
+func (c *Command) Editor() (string, error) {
+	output, err := c.editor().Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// RevParse returns the object name of the revision, e.g. HEAD.
+func (c *Command) RevParse(rev string) (string, error) {
+	output, err := c.revParse(rev).Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// HookPath returns the path of the hooks directory resolved by git,
+// which honors the core.hooksPath setting.
+func (c *Command) HookPath() (string, error) {
+	output, err := c.hookPath().Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
 // Diff compares the differences between two sets of data.
 // It returns a string representing the differences and an error.
 // If there are no differences, it returns an empty string and an error.
 func (c *Command) DiffFiles() (string, error) {
+	c.ignoreList = nil
 	output, err := c.diffNames().Output()
 	if err != nil {
 		return "", err
@@ -154,44 +337,87 @@ func (c *Command) DiffFiles() (string, error) {
 		return "", errors.New("please add your staged changes using git add <files...>")
 	}
 
+	root, err := c.TopLevel()
+	if err != nil {
+		return "", err
+	}
+	fs := osfs.New(strings.TrimSpace(root))
+
+	kept, ignored := withoutIgnored(fs, splitNames(string(output)))
+	if len(kept) == 0 {
+		return "", errAllIgnored
+	}
+	c.ignoreList = ignored
+	defer func() { c.ignoreList = nil }()
+
 	output, err = c.diffFiles().Output()
 	if err != nil {
 		return "", err
 	}
 
-	return string(output), nil
+	if c.includeGenerated {
+		return string(output), nil
+	}
+
+	return filterDiff(string(output), fs)
 }
 
-func (c *Command) InstallHook() error {
-	hookPath, err := c.hookPath().Output()
+// DiffNames returns the names of the changed files, leaving out the files ignored by .codegptignore.
+func (c *Command) DiffNames() ([]string, error) {
+	output, err := c.diffNames().Output()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	root, err := c.TopLevel()
+	if err != nil {
+		return nil, err
 	}
+	kept, _ := withoutIgnored(osfs.New(strings.TrimSpace(root)), splitNames(string(output)))
+
+	return kept, nil
+}
 
-	target := path.Join(strings.TrimSpace(string(hookPath)), HookPrepareCommitMessageTemplate)
-	if file.IsFile(target) {
-		return errors.New("hook file prepare-commit-msg exist.")
+// splitNames splits the output of `git diff --name-only` into unquoted file names.
+func splitNames(output string) []string {
+	names := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			names = append(names, unquote(line))
+		}
 	}
+	return names
+}
 
-	content, err := util.GetTemplateByBytes(HookPrepareCommitMessageTemplate, nil)
+// InstallHook installs the git hook with the given name, e.g. prepare-commit-msg.
+// If the hook script already exists, the original is backed up and the codegpt block
+// is appended to it, so both run.
+func (c *Command) InstallHook(name string) error {
+	hookPath, err := c.HookPath()
 	if err != nil {
 		return err
 	}
-
-	return os.WriteFile(target, content, 0o755)
+	return installHook(hookPath, name)
 }
 
-func (c *Command) UninstallHook() error {
-	hookPath, err := c.hookPath().Output()
+// UninstallHook removes the codegpt block from the git hook with the given name.
+// The original hook script is restored from the backup if it wasn't changed since,
+// and the hook file is removed if nothing else is left.
+func (c *Command) UninstallHook(name string) error {
+	hookPath, err := c.HookPath()
 	if err != nil {
 		return err
 	}
+	return uninstallHook(hookPath, name)
+}
 
-	target := path.Join(strings.TrimSpace(string(hookPath)), HookPrepareCommitMessageTemplate)
-	if !file.IsFile(target) {
-		return errors.New("hook file prepare-commit-msg is not exist.")
+// IsHookInstalled reports whether the git hook with the given name exists and is managed by codegpt.
+func (c *Command) IsHookInstalled(name string) (bool, error) {
+	hookPath, err := c.HookPath()
+	if err != nil {
+		return false, err
 	}
-	return os.Remove(target)
+	return isHookInstalled(hookPath, name)
 }
 
 func New(opts ...Option) *Command {
@@ -207,5 +433,14 @@ func New(opts ...Option) *Command {
 		diffUnified: cfg.diffUnified,
 		excludeList: append(excludeFromDiff, cfg.excludeList...),
 		isAmend:     cfg.isAmend,
+		diffFrom:    cfg.diffFrom,
+		diffTo:      cfg.diffTo,
+		signoff:     cfg.signoff,
+		gpgSign:     cfg.gpgSign,
+		verify:      cfg.verify,
+		author:      cfg.author,
+		coAuthors:   cfg.coAuthors,
+
+		includeGenerated: cfg.includeGenerated,
 	}
 }
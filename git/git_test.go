@@ -0,0 +1,102 @@
+package git
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommandCommit(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{
+			name: "defaults",
+			want: []string{"git", "commit", "--no-verify", "--message=feat: add cache"},
+		},
+		{
+			name: "verify",
+			opts: []Option{WithVerify(true)},
+			want: []string{"git", "commit", "--message=feat: add cache"},
+		},
+		{
+			name: "signoff",
+			opts: []Option{WithVerify(true), WithSignoff(true)},
+			want: []string{"git", "commit", "--signoff", "--message=feat: add cache"},
+		},
+		{
+			name: "no signoff",
+			opts: []Option{WithVerify(true), WithSignoff(false)},
+			want: []string{"git", "commit", "--message=feat: add cache"},
+		},
+		{
+			name: "gpg sign with the default key",
+			opts: []Option{WithVerify(true), WithGPGSign("true")},
+			want: []string{"git", "commit", "--gpg-sign", "--message=feat: add cache"},
+		},
+		{
+			name: "no gpg sign",
+			opts: []Option{WithVerify(true), WithGPGSign("false")},
+			want: []string{"git", "commit", "--no-gpg-sign", "--message=feat: add cache"},
+		},
+		{
+			name: "gpg sign with a key",
+			opts: []Option{WithVerify(true), WithGPGSign("3AA5C34371567BD2")},
+			want: []string{"git", "commit", "--gpg-sign=3AA5C34371567BD2", "--message=feat: add cache"},
+		},
+		{
+			name: "author",
+			opts: []Option{WithVerify(true), WithAuthor("A U Thor <author@example.com>")},
+			want: []string{"git", "commit", "--author=A U Thor <author@example.com>", "--message=feat: add cache"},
+		},
+		{
+			name: "co-authors",
+			opts: []Option{WithVerify(true), WithCoAuthors([]string{"Foo <foo@example.com>", "Bar <bar@example.com>"})},
+			want: []string{
+				"git", "commit",
+				"--trailer=Co-authored-by: Foo <foo@example.com>",
+				"--trailer=Co-authored-by: Bar <bar@example.com>",
+				"--message=feat: add cache",
+			},
+		},
+		{
+			name: "all",
+			opts: []Option{
+				WithSignoff(true),
+				WithGPGSign("true"),
+				WithAuthor("A U Thor <author@example.com>"),
+				WithCoAuthors([]string{"Foo <foo@example.com>"}),
+				WithEnableAmend(true),
+			},
+			want: []string{
+				"git", "commit", "--no-verify", "--signoff", "--gpg-sign",
+				"--author=A U Thor <author@example.com>",
+				"--trailer=Co-authored-by: Foo <foo@example.com>",
+				"--message=feat: add cache",
+				"--amend",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(tt.opts...).commit("feat: add cache").Args; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandDiffFormat(t *testing.T) {
+	// the user config can't change the output parsed by ParseDiff
+	for _, cmd := range []*exec.Cmd{New().diffNames(), New().diffFiles()} {
+		args := strings.Join(cmd.Args, " ")
+		for _, flag := range []string{"--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/"} {
+			if !strings.Contains(args, flag) {
+				t.Errorf("%s is missing %s", args, flag)
+			}
+		}
+	}
+}
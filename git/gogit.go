@@ -0,0 +1,583 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/utils/binary"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// GoGit is the Repository implemented with go-git, which works without the git binary.
+// It doesn't run git hooks, sign commits or amend commits.
+type GoGit struct {
+	repo *gogit.Repository
+	cfg  *config
+}
+
+// OpenGoGit opens the repository containing the path with go-git.
+func OpenGoGit(path string, opts ...Option) (*GoGit, error) {
+	repo, err := gogit.PlainOpenWithOptions(path, &gogit.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewGoGit(repo, opts...), nil
+}
+
+// NewGoGit returns the Repository for the go-git repository, which may be stored in memory.
+func NewGoGit(repo *gogit.Repository, opts ...Option) *GoGit {
+	cfg := &config{}
+
+	// Loop through each option
+	for _, o := range opts {
+		// Call the option giving the instantiated
+		o.apply(cfg)
+	}
+	cfg.excludeList = append(append([]string{}, excludeFromDiff...), cfg.excludeList...)
+
+	return &GoGit{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+// entry is a file of a tree or the index.
+type entry struct {
+	hash plumbing.Hash
+	mode filemode.FileMode
+}
+
+// treeEntries returns the files of the commit tree, or no file for the empty tree.
+func (g *GoGit) treeEntries(rev string) (map[string]entry, error) {
+	entries := map[string]entry{}
+	if rev == EmptyTreeSHA {
+		return entries, nil
+	}
+
+	hash, err := g.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, err
+	}
+	commit, err := g.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tree.Files().ForEach(func(f *object.File) error {
+		entries[f.Name] = entry{hash: f.Hash, mode: f.Mode}
+		return nil
+	})
+
+	return entries, err
+}
+
+// indexEntries returns the staged files.
+func (g *GoGit) indexEntries() (map[string]entry, error) {
+	idx, err := g.repo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := map[string]entry{}
+	for _, e := range idx.Entries {
+		entries[e.Name] = entry{hash: e.Hash, mode: e.Mode}
+	}
+
+	return entries, nil
+}
+
+// headOrEmpty returns the revision if it exists, or the empty tree, e.g. before the first commit.
+func (g *GoGit) headOrEmpty(rev string) string {
+	if _, err := g.repo.ResolveRevision(plumbing.Revision(rev)); err != nil {
+		return EmptyTreeSHA
+	}
+	return rev
+}
+
+// changes returns the files of both sides of the diff which differ, sorted by name.
+func (g *GoGit) changes() ([]string, map[string]entry, map[string]entry, error) {
+	var from, to map[string]entry
+	var err error
+
+	switch {
+	case g.cfg.diffTo != "":
+		if from, err = g.treeEntries(g.cfg.diffFrom); err != nil {
+			return nil, nil, nil, err
+		}
+		if to, err = g.treeEntries(g.cfg.diffTo); err != nil {
+			return nil, nil, nil, err
+		}
+	default:
+		base := "HEAD"
+		if g.cfg.isAmend {
+			// the amended commit includes the changes of HEAD and the staged changes
+			base = "HEAD^"
+		}
+		if from, err = g.treeEntries(g.headOrEmpty(base)); err != nil {
+			return nil, nil, nil, err
+		}
+		if to, err = g.indexEntries(); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	names := []string{}
+	for name, e := range to {
+		if old, ok := from[name]; !ok || old != e {
+			names = append(names, name)
+		}
+	}
+	for name := range from {
+		if _, ok := to[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	filtered := []string{}
+	for _, name := range names {
+		excluded := false
+		for _, pattern := range g.cfg.excludeList {
+			if matchPathspec(pattern, name) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			filtered = append(filtered, name)
+		}
+	}
+	sort.Strings(filtered)
+
+	return filtered, from, to, nil
+}
+
+// DiffNames returns the names of the changed files, leaving out the files ignored by .codegptignore.
+func (g *GoGit) DiffNames() ([]string, error) {
+	names, _, _, err := g.changes()
+	if err != nil {
+		return nil, err
+	}
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	names, _ = withoutIgnored(wt.Filesystem, names)
+	return names, nil
+}
+
+// DiffFiles returns the unified diff of the changes.
+// If there are no differences, it returns an empty string and an error.
+func (g *GoGit) DiffFiles() (string, error) {
+	names, from, to, err := g.changes()
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", errors.New("please add your staged changes using git add <files...>")
+	}
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	if names, _ = withoutIgnored(wt.Filesystem, names); len(names) == 0 {
+		return "", errAllIgnored
+	}
+
+	p := &patch{}
+	for _, name := range names {
+		fp, err := g.filePatch(name, from, to)
+		if err != nil {
+			return "", err
+		}
+		p.filePatches = append(p.filePatches, fp)
+	}
+
+	contextLines := g.cfg.diffUnified
+	if contextLines == 0 {
+		contextLines = 3
+	}
+	var buf bytes.Buffer
+	if err := fdiff.NewUnifiedEncoder(&buf, contextLines).Encode(p); err != nil {
+		return "", err
+	}
+
+	if g.cfg.includeGenerated {
+		return buf.String(), nil
+	}
+
+	return filterDiff(buf.String(), wt.Filesystem)
+}
+
+func (g *GoGit) blob(hash plumbing.Hash) (string, bool, error) {
+	b, err := g.repo.BlobObject(hash)
+	if err != nil {
+		return "", false, err
+	}
+	r, err := b.Reader()
+	if err != nil {
+		return "", false, err
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", false, err
+	}
+	isBinary, err := binary.IsBinary(bytes.NewReader(content))
+	if err != nil {
+		return "", false, err
+	}
+
+	return string(content), isBinary, nil
+}
+
+func (g *GoGit) filePatch(name string, from, to map[string]entry) (*filePatch, error) {
+	fp := &filePatch{}
+	var src, dst string
+
+	if e, ok := from[name]; ok {
+		fp.from = &patchFile{path: name, entry: e}
+		content, isBinary, err := g.blob(e.hash)
+		if err != nil {
+			return nil, err
+		}
+		src, fp.isBinary = content, fp.isBinary || isBinary
+	}
+	if e, ok := to[name]; ok {
+		fp.to = &patchFile{path: name, entry: e}
+		content, isBinary, err := g.blob(e.hash)
+		if err != nil {
+			return nil, err
+		}
+		dst, fp.isBinary = content, fp.isBinary || isBinary
+	}
+
+	if fp.isBinary {
+		return fp, nil
+	}
+
+	for _, d := range diff.Do(src, dst) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		}
+		fp.chunks = append(fp.chunks, &chunk{content: d.Text, op: op})
+	}
+
+	return fp, nil
+}
+
+// TopLevel returns the path of the top-level directory of the working tree.
+func (g *GoGit) TopLevel() (string, error) {
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	return wt.Filesystem.Root(), nil
+}
+
+// HookPath returns the path of the hooks directory, which honors the core.hooksPath setting.
+func (g *GoGit) HookPath() (string, error) {
+	cfg, err := g.repo.Config()
+	if err != nil {
+		return "", err
+	}
+
+	if hooksPath := cfg.Raw.Section("core").Option("hooksPath"); hooksPath != "" {
+		if filepath.IsAbs(hooksPath) {
+			return hooksPath, nil
+		}
+		root, err := g.TopLevel()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(root, hooksPath), nil
+	}
+
+	storage, ok := g.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", errors.New("the repository is not stored on disk")
+	}
+
+	return filepath.Join(storage.Filesystem().Root(), "hooks"), nil
+}
+
+// InstallHook installs the git hook with the given name, e.g. prepare-commit-msg.
+// The hooks are only run by the git binary, go-git commits skip them.
+func (g *GoGit) InstallHook(name string) error {
+	hookPath, err := g.HookPath()
+	if err != nil {
+		return err
+	}
+	return installHook(hookPath, name)
+}
+
+// UninstallHook removes the codegpt block from the git hook with the given name.
+func (g *GoGit) UninstallHook(name string) error {
+	hookPath, err := g.HookPath()
+	if err != nil {
+		return err
+	}
+	return uninstallHook(hookPath, name)
+}
+
+// IsHookInstalled reports whether the git hook with the given name exists and is managed by codegpt.
+func (g *GoGit) IsHookInstalled(name string) (bool, error) {
+	hookPath, err := g.HookPath()
+	if err != nil {
+		return false, err
+	}
+	return isHookInstalled(hookPath, name)
+}
+
+// CurrentBranch returns the name of the current branch.
+// In detached HEAD state, it returns an empty string.
+func (g *GoGit) CurrentBranch() (string, error) {
+	// HEAD may point to a branch without commits yet
+	head, err := g.repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", err
+	}
+	if head.Type() == plumbing.SymbolicReference && head.Target().IsBranch() {
+		return head.Target().Short(), nil
+	}
+
+	return "", nil
+}
+
+// Log returns the last n commit messages of the current branch, excluding merge commits.
+func (g *GoGit) Log(n int) ([]string, error) {
+	messages := []string{}
+	if _, err := g.repo.Head(); errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return messages, nil
+	}
+
+	iter, err := g.repo.Log(&gogit.LogOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	for len(messages) < n {
+		commit, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if commit.NumParents() > 1 {
+			continue
+		}
+		if message := strings.TrimSpace(commit.Message); message != "" {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages, nil
+}
+
+// RevParse returns the object name of the revision, e.g. HEAD.
+func (g *GoGit) RevParse(rev string) (string, error) {
+	hash, err := g.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return "", err
+	}
+
+	return hash.String(), nil
+}
+
+// Editor returns the editor configured for git, honoring
+// $GIT_EDITOR, core.editor, $VISUAL and $EDITOR in that order.
+func (g *GoGit) Editor() (string, error) {
+	if editor := os.Getenv("GIT_EDITOR"); editor != "" {
+		return editor, nil
+	}
+
+	if cfg, err := g.repo.ConfigScoped(gitconfig.SystemScope); err == nil {
+		if editor := cfg.Raw.Section("core").Option("editor"); editor != "" {
+			return editor, nil
+		}
+	}
+
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(key); editor != "" {
+			return editor, nil
+		}
+	}
+
+	return "vi", nil
+}
+
+var identPattern = regexp.MustCompile(`^\s*(.*?)\s*<([^>]*)>\s*$`)
+
+// trailerLine matches the lines of a trailer block like `Signed-off-by: A U Thor <author@example.com>`.
+var trailerLine = regexp.MustCompile(`^[\w-]+: `)
+
+// appendTrailers adds the trailers to the last paragraph of the message if it is a trailer
+// block already, or as a new paragraph.
+func appendTrailers(message string, trailers []string) string {
+	message = strings.TrimRight(message, "\n")
+	if len(trailers) == 0 {
+		return message + "\n"
+	}
+
+	paragraphs := strings.Split(message, "\n\n")
+	last := strings.Split(paragraphs[len(paragraphs)-1], "\n")
+	isTrailers := len(paragraphs) > 1
+	for _, line := range last {
+		if !trailerLine.MatchString(line) {
+			isTrailers = false
+		}
+	}
+
+	separator := "\n\n"
+	if isTrailers {
+		separator = "\n"
+	}
+	for _, trailer := range trailers {
+		if isTrailers && strings.Contains(message, trailer) {
+			continue
+		}
+		message += separator + trailer
+		separator = "\n"
+	}
+
+	return message + "\n"
+}
+
+// Commit records the staged changes with the commit message and returns a summary like git does.
+func (g *GoGit) Commit(val string) (string, error) {
+	switch {
+	case g.cfg.isAmend:
+		return "", errors.New("amend is not supported by the go-git backend")
+	case g.cfg.gpgSign != "" && g.cfg.gpgSign != "false":
+		return "", errors.New("signing commits is not supported by the go-git backend")
+	case g.cfg.verify:
+		return "", errors.New("running git hooks is not supported by the go-git backend")
+	}
+
+	cfg, err := g.repo.ConfigScoped(gitconfig.SystemScope)
+	if err != nil {
+		return "", err
+	}
+	if cfg.User.Name == "" || cfg.User.Email == "" {
+		return "", errors.New("please tell me who you are: git config user.name and user.email are not set")
+	}
+	committer := &object.Signature{Name: cfg.User.Name, Email: cfg.User.Email, When: time.Now()}
+	author := committer
+	if g.cfg.author != "" {
+		match := identPattern.FindStringSubmatch(g.cfg.author)
+		if match == nil {
+			return "", errors.New("invalid author, ex: A U Thor <author@example.com>")
+		}
+		author = &object.Signature{Name: match[1], Email: match[2], When: committer.When}
+	}
+
+	trailers := []string{}
+	if g.cfg.signoff {
+		trailers = append(trailers, fmt.Sprintf("Signed-off-by: %s <%s>", committer.Name, committer.Email))
+	}
+	for _, coAuthor := range g.cfg.coAuthors {
+		trailers = append(trailers, "Co-authored-by: "+coAuthor)
+	}
+	message := appendTrailers(val, trailers)
+
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	hash, err := wt.Commit(message, &gogit.CommitOptions{
+		Author:    author,
+		Committer: committer,
+	})
+	if err != nil {
+		return "", fmt.Errorf("git commit failed: %w", err)
+	}
+
+	branch, err := g.CurrentBranch()
+	if err != nil {
+		return "", err
+	}
+	if branch == "" {
+		branch = "detached HEAD"
+	}
+	title, _, _ := strings.Cut(message, "\n")
+
+	return fmt.Sprintf("[%s %s] %s\n", branch, hash.String()[:7], title), nil
+}
+
+// patch implements the go-git diff.Patch interface for the unified encoder.
+type patch struct {
+	filePatches []fdiff.FilePatch
+}
+
+func (p *patch) FilePatches() []fdiff.FilePatch { return p.filePatches }
+func (p *patch) Message() string                { return "" }
+
+type filePatch struct {
+	from, to *patchFile
+	isBinary bool
+	chunks   []fdiff.Chunk
+}
+
+func (p *filePatch) IsBinary() bool { return p.isBinary }
+
+func (p *filePatch) Files() (fdiff.File, fdiff.File) {
+	// avoid returning typed nil pointers as non-nil interfaces
+	var from, to fdiff.File
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+func (p *filePatch) Chunks() []fdiff.Chunk { return p.chunks }
+
+type patchFile struct {
+	path  string
+	entry entry
+}
+
+func (f *patchFile) Hash() plumbing.Hash     { return f.entry.hash }
+func (f *patchFile) Mode() filemode.FileMode { return f.entry.mode }
+func (f *patchFile) Path() string            { return f.path }
+
+type chunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c *chunk) Content() string       { return c.content }
+func (c *chunk) Type() fdiff.Operation { return c.op }
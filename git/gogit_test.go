@@ -0,0 +1,207 @@
+package git
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+func writeFile(t *testing.T, fs billy.Filesystem, name, content string) {
+	t.Helper()
+	f, err := fs.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testRepository(t *testing.T) (*gogit.Repository, *gogit.Worktree, billy.Filesystem) {
+	t.Helper()
+	fs := memfs.New()
+	repo, err := gogit.Init(memory.NewStorage(), fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.User.Name = "A U Thor"
+	cfg.User.Email = "author@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "main.go", "package main\n\nfunc main() {\n}\n")
+	writeFile(t, fs, "go.sum", "example.com/foo v1.0.0 h1:abc=\n")
+	if _, err := wt.Add("."); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "A U Thor", Email: "author@example.com", When: time.Now()}
+	if _, err := wt.Commit("feat: initial commit", &gogit.CommitOptions{Author: sig}); err != nil {
+		t.Fatal(err)
+	}
+
+	return repo, wt, fs
+}
+
+func TestGoGitDiff(t *testing.T) {
+	repo, wt, fs := testRepository(t)
+	g := NewGoGit(repo, WithDiffUnified(1))
+
+	if _, err := g.DiffFiles(); err == nil {
+		t.Fatal("DiffFiles() expected error without staged changes")
+	}
+
+	writeFile(t, fs, "main.go", "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n")
+	writeFile(t, fs, "go.sum", "example.com/foo v1.1.0 h1:def=\n")
+	writeFile(t, fs, "README.md", "# hello\n")
+	if _, err := wt.Add("."); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := g.DiffNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "README.md,main.go" {
+		t.Errorf("DiffNames() = %v, want [README.md main.go]", names)
+	}
+
+	diff, err := g.DiffFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"diff --git a/README.md b/README.md",
+		"new file mode 100644",
+		"+# hello",
+		"diff --git a/main.go b/main.go",
+		"@@ -3,2 +3,3 @@",
+		"+\tprintln(\"hello\")",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("DiffFiles() missing %q in\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "go.sum") {
+		t.Errorf("DiffFiles() should exclude go.sum\n%s", diff)
+	}
+}
+
+func TestGoGitIgnoreFile(t *testing.T) {
+	repo, wt, fs := testRepository(t)
+	g := NewGoGit(repo)
+
+	writeFile(t, fs, IgnoreFile, "secrets/\n")
+	writeFile(t, fs, "secrets/token.txt", "s3cr3t\n")
+	if _, err := wt.Add("secrets"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.DiffFiles(); err != errAllIgnored {
+		t.Errorf("DiffFiles() error = %v, want %v", err, errAllIgnored)
+	}
+
+	writeFile(t, fs, "README.md", "# hello\n")
+	if _, err := wt.Add("README.md"); err != nil {
+		t.Fatal(err)
+	}
+	names, err := g.DiffNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "README.md" {
+		t.Errorf("DiffNames() = %v, want [README.md]", names)
+	}
+	diff, err := g.DiffFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(diff, "s3cr3t") {
+		t.Errorf("DiffFiles() should leave out the ignored files\n%s", diff)
+	}
+}
+
+func TestGoGitCommit(t *testing.T) {
+	repo, wt, fs := testRepository(t)
+	g := NewGoGit(repo,
+		WithSignoff(true),
+		WithCoAuthors([]string{"Jane Doe <jane@example.com>"}),
+	)
+
+	branch, err := g.CurrentBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branch != "master" {
+		t.Errorf("CurrentBranch() = %q, want master", branch)
+	}
+
+	writeFile(t, fs, "README.md", "# hello\n")
+	if _, err := wt.Add("README.md"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Commit("docs: add readme\n\nRefs: PROJ-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := g.Log(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "docs: add readme\n\n" +
+		"Refs: PROJ-1\n" +
+		"Signed-off-by: A U Thor <author@example.com>\n" +
+		"Co-authored-by: Jane Doe <jane@example.com>"
+	if len(messages) != 2 || messages[0] != want || messages[1] != "feat: initial commit" {
+		t.Errorf("Log() = %q, want [%q, %q]", messages, want, "feat: initial commit")
+	}
+
+	head, err := g.RevParse("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(head) != 40 {
+		t.Errorf("RevParse() = %q, want a commit SHA", head)
+	}
+
+	if _, err := NewGoGit(repo, WithEnableAmend(true)).Commit("fix: amend"); err == nil {
+		t.Error("Commit() expected error for amend")
+	}
+}
+
+func TestMatchPathspec(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"go.sum", "go.sum", true},
+		{"go.sum", "sub/go.sum", false},
+		{"*.lock", "sub/yarn.lock", true},
+		{"vendor", "vendor/foo/bar.go", true},
+		{"vendor/", "vendor/foo/bar.go", true},
+		{"docs/*.md", "docs/guide/intro.md", true},
+		{"main.go", "main.go.orig", false},
+	}
+	for _, tt := range tests {
+		if got := matchPathspec(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchPathspec(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
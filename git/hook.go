@@ -2,9 +2,15 @@ package git
 
 import (
 	"embed"
+	"errors"
 	"log"
+	"os"
+	"path"
+	"regexp"
+	"strings"
 
 	"github.com/appleboy/CodeGPT/util"
+	"github.com/appleboy/com/file"
 )
 
 //go:embed templates/*
@@ -12,11 +18,296 @@ var files embed.FS
 
 const (
 	HookPrepareCommitMessageTemplate = "prepare-commit-msg"
+	HookCommitMessageTemplate        = "commit-msg"
+	HookPrePushTemplate              = "pre-push"
 	CommitMessageTemplate            = "commit-msg.tmpl"
 )
 
+// Hooks are the names of the git hooks which can be installed.
+var Hooks = []string{
+	HookPrepareCommitMessageTemplate,
+	HookCommitMessageTemplate,
+	HookPrePushTemplate,
+}
+
+const (
+	// hookBlockStart and hookBlockEnd surround the lines codegpt owns in a hook script.
+	hookBlockStart = "# >>> codegpt >>>"
+	hookBlockEnd   = "# <<< codegpt <<<"
+	// hookBackupSuffix is appended to the name of the original hook script backup.
+	hookBackupSuffix = ".codegpt.bak"
+	// legacyPrepareCommitMessageHook is the prepare-commit-msg script installed by older
+	// versions, before the hooks had a managed block.
+	legacyPrepareCommitMessageHook = "#!/bin/sh\n\ncodegpt commit --file $1 --preview\n"
+	// prePushChainBlock runs the review first when chained into an existing pre-push hook,
+	// saving the pushed refs read from stdin so the rest of the hook can still read them.
+	prePushChainBlock = hookBlockStart + `
+codegpt_refs=$(mktemp) || exit 1
+cat > "$codegpt_refs"
+codegpt hook run pre-push "$@" < "$codegpt_refs" || { rm -f "$codegpt_refs"; exit 1; }
+exec < "$codegpt_refs"
+rm -f "$codegpt_refs"
+` + hookBlockEnd
+)
+
+// execCommand matches the lines replacing the shell process with another command,
+// unlike `exec` with only redirections like `exec 2>&1` or `exec < file`.
+var execCommand = regexp.MustCompile(`^\s*(?:.*(?:then|else|do|&&|\|\||;)\s+)?exec\s+[^<>&0-9\s]`)
+
 func init() {
 	if err := util.LoadTemplates(files); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// hookBlock returns the managed block of the hook script including its markers,
+// or an empty string if the script has no managed block.
+func hookBlock(script string) string {
+	start := strings.Index(script, hookBlockStart)
+	if start < 0 {
+		return ""
+	}
+	end := strings.Index(script[start:], hookBlockEnd)
+	if end < 0 {
+		return ""
+	}
+
+	return script[start : start+end+len(hookBlockEnd)]
+}
+
+// isLegacyHook reports whether the script is exactly the hook installed by older versions.
+func isLegacyHook(name, script string) bool {
+	return name == HookPrepareCommitMessageTemplate && script == legacyPrepareCommitMessageHook
+}
+
+// chainHookBlock adds the managed block of the hook to an existing shell hook script.
+// The pre-push block runs first, because the script may consume the pushed refs read from stdin,
+// like `git lfs pre-push "$@"`. Other blocks are appended to the script.
+func chainHookBlock(name, script, block string) (string, error) {
+	if name != HookPrePushTemplate {
+		return appendHookBlock(script, block)
+	}
+
+	if hookBlock(script) != "" {
+		return "", errors.New("hook already contains the codegpt block")
+	}
+	lines := strings.Split(strings.TrimRight(script, "\n"), "\n")
+	if !isShellScript(lines[0]) {
+		return "", errors.New("hook is not a shell script, call codegpt from it manually")
+	}
+
+	if !strings.HasPrefix(lines[0], "#!") {
+		return prePushChainBlock + "\n" + script, nil
+	}
+	result := append([]string{lines[0], "", prePushChainBlock}, lines[1:]...)
+	return strings.Join(result, "\n") + "\n", nil
+}
+
+// appendHookBlock adds the managed block to an existing shell hook script.
+// The block is inserted before a trailing exit command so it still runs.
+// Scripts replacing themselves with exec are refused, the block would never run.
+func appendHookBlock(script, block string) (string, error) {
+	if hookBlock(script) != "" {
+		return "", errors.New("hook already contains the codegpt block")
+	}
+
+	lines := strings.Split(strings.TrimRight(script, "\n"), "\n")
+	if !isShellScript(lines[0]) {
+		return "", errors.New("hook is not a shell script, call codegpt from it manually")
+	}
+	for _, line := range lines {
+		if execCommand.MatchString(line) {
+			return "", errors.New("hook replaces itself with `exec`, so codegpt would never run. " +
+				"Call `codegpt hook run` from it manually before the exec line")
+		}
+	}
+
+	index := len(lines)
+	if last := strings.TrimSpace(lines[index-1]); last == "exit" || strings.HasPrefix(last, "exit ") {
+		index--
+	}
+
+	result := append([]string{}, lines[:index]...)
+	result = append(result, "", block)
+	result = append(result, lines[index:]...)
+
+	return strings.Join(result, "\n") + "\n", nil
+}
+
+// isShellScript reports whether the first line of the script runs it with a POSIX shell.
+// Scripts without a shebang are run by git with sh.
+func isShellScript(firstLine string) bool {
+	if !strings.HasPrefix(firstLine, "#!") {
+		return true
+	}
+	for _, field := range strings.Fields(strings.TrimPrefix(firstLine, "#!")) {
+		switch path.Base(field) {
+		case "sh", "bash", "dash", "ksh", "zsh":
+			return true
+		}
+	}
+	return false
+}
+
+// removeHookBlock removes the managed block from the hook script. It reports false if the
+// script has no managed block. The returned script is empty if nothing but the shebang is left.
+func removeHookBlock(script string) (string, bool) {
+	block := hookBlock(script)
+	if block == "" {
+		return script, false
+	}
+
+	// the block is appended after a blank line, or is the first line of a script without shebang
+	switch {
+	case strings.Contains(script, "\n\n"+block):
+		block = "\n\n" + block
+	case strings.HasPrefix(script, block+"\n"):
+		block += "\n"
+	}
+	script = strings.Replace(script, block, "", 1)
+
+	rest := strings.TrimSpace(script)
+	if rest == "" || (strings.HasPrefix(rest, "#!") && !strings.Contains(rest, "\n")) {
+		return "", true
+	}
+
+	return script, true
+}
+
+// installHook installs the git hook with the given name, e.g. prepare-commit-msg, in the hooks directory.
+// If the hook script already exists, the original is backed up and the codegpt block
+// is appended to it, so both run.
+func installHook(hookPath, name string) error {
+	// core.hooksPath may point to a folder which doesn't exist yet
+	if err := os.MkdirAll(hookPath, 0o755); err != nil {
+		return err
+	}
+
+	content, err := util.GetTemplateByBytes(name, nil)
+	if err != nil {
+		return err
+	}
+
+	target := path.Join(hookPath, name)
+	if !file.IsFile(target) {
+		return os.WriteFile(target, content, 0o755)
+	}
+
+	original, err := os.ReadFile(target)
+	if err != nil {
+		return err
+	}
+	if hookBlock(string(original)) != "" || isLegacyHook(name, string(original)) {
+		return errors.New("hook file " + name + " is already installed.")
+	}
+
+	script, err := chainHookBlock(name, string(original), hookBlock(string(content)))
+	if err != nil {
+		return errors.New("hook file " + name + " exist: " + err.Error())
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+	backup := target + hookBackupSuffix
+	if !file.IsFile(backup) {
+		if err := os.WriteFile(backup, original, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(target, []byte(script), info.Mode().Perm()); err != nil {
+		return err
+	}
+	// make sure the chained hook is executable
+	return os.Chmod(target, info.Mode().Perm()|0o111)
+}
+
+// uninstallHook removes the codegpt block from the git hook with the given name.
+// The original hook script is restored from the backup if it wasn't changed since,
+// and the hook file is removed if nothing else is left.
+func uninstallHook(hookPath, name string) error {
+	target := path.Join(hookPath, name)
+	if !file.IsFile(target) {
+		return errors.New("hook file " + name + " is not exist.")
+	}
+
+	content, err := os.ReadFile(target)
+	if err != nil {
+		return err
+	}
+
+	script, ok := removeHookBlock(string(content))
+	if !ok {
+		// hooks installed by older versions don't have the managed block
+		if isLegacyHook(name, string(content)) {
+			return os.Remove(target)
+		}
+		return errors.New("hook file " + name + " has no codegpt block, remove the codegpt call from it manually.")
+	}
+
+	backup := target + hookBackupSuffix
+	if file.IsFile(backup) {
+		original, err := os.ReadFile(backup)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(string(original)) == strings.TrimSpace(script) {
+			return os.Rename(backup, target)
+		}
+	}
+
+	if script == "" {
+		return os.Remove(target)
+	}
+
+	return os.WriteFile(target, []byte(script), 0o755)
+}
+
+// isHookInstalled reports whether the git hook with the given name exists and is managed by codegpt.
+func isHookInstalled(hookPath, name string) (bool, error) {
+	target := path.Join(hookPath, name)
+	if !file.IsFile(target) {
+		return false, nil
+	}
+
+	content, err := os.ReadFile(target)
+	if err != nil {
+		return false, err
+	}
+
+	return hookBlock(string(content)) != "" || isLegacyHook(name, string(content)), nil
+}
+
+// HookManager returns the name of another hook manager (husky or pre-commit)
+// which owns the hooks directory, or an empty string if none is detected.
+// husky points core.hooksPath to its .husky folder.
+func HookManager(hookPath string) string {
+	if strings.Contains(hookPath, ".husky") {
+		return "husky"
+	}
+
+	entries, err := os.ReadDir(hookPath)
+	if err != nil {
+		return ""
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".sample") {
+			continue
+		}
+		content, err := os.ReadFile(path.Join(hookPath, entry.Name()))
+		if err != nil {
+			continue
+		}
+		switch {
+		case strings.Contains(string(content), "husky"):
+			return "husky"
+		case strings.Contains(string(content), "File generated by pre-commit"):
+			return "pre-commit"
+		}
+	}
+
+	return ""
+}
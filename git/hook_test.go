@@ -0,0 +1,104 @@
+package git
+
+import "testing"
+
+func TestHookBlock(t *testing.T) {
+	block := hookBlockStart + "\ncodegpt commit --file $1 --preview\n" + hookBlockEnd
+
+	tests := []struct {
+		name     string
+		original string
+		want     string
+	}{
+		{
+			name:     "append",
+			original: "#!/bin/sh\n\necho hello\n",
+			want:     "#!/bin/sh\n\necho hello\n\n" + block + "\n",
+		},
+		{
+			name:     "before trailing exit",
+			original: "#!/usr/bin/env bash\necho hello\nexit 0\n",
+			want:     "#!/usr/bin/env bash\necho hello\n\n" + block + "\nexit 0\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := appendHookBlock(tt.original, block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("appendHookBlock() = %q, want %q", got, tt.want)
+			}
+
+			removed, ok := removeHookBlock(got)
+			if !ok || removed != tt.original {
+				t.Errorf("removeHookBlock() = %q, %v, want %q, true", removed, ok, tt.original)
+			}
+		})
+	}
+
+	if _, err := appendHookBlock("#!/usr/bin/env python3\nprint('hello')\n", block); err == nil {
+		t.Error("appendHookBlock() expected error for python script")
+	}
+
+	if removed, ok := removeHookBlock("#!/bin/sh\n\n" + block + "\n"); !ok || removed != "" {
+		t.Errorf("removeHookBlock() = %q, %v, want empty script", removed, ok)
+	}
+	if _, ok := removeHookBlock("#!/bin/sh\necho hello\n"); ok {
+		t.Error("removeHookBlock() = true for script without block")
+	}
+
+	if _, err := appendHookBlock("#!/bin/sh\nif [ -x venv/bin/pre-commit ]; then\n  exec venv/bin/pre-commit \"$@\"\nfi\n", block); err == nil {
+		t.Error("appendHookBlock() expected error for script replacing itself with exec")
+	}
+	if _, err := appendHookBlock("#!/bin/sh\nexec 1>&2\necho hello\n", block); err != nil {
+		t.Errorf("appendHookBlock() error = %v for exec with redirections only", err)
+	}
+}
+
+func TestChainPrePushHook(t *testing.T) {
+	block := hookBlockStart + "\ncodegpt hook run pre-push \"$@\"\n" + hookBlockEnd
+
+	tests := []struct {
+		name     string
+		original string
+		want     string
+	}{
+		{
+			name:     "before the script consuming stdin",
+			original: "#!/bin/sh\ngit lfs pre-push \"$@\"\n",
+			want:     "#!/bin/sh\n\n" + prePushChainBlock + "\ngit lfs pre-push \"$@\"\n",
+		},
+		{
+			name:     "script without shebang",
+			original: "git lfs pre-push \"$@\"\n",
+			want:     prePushChainBlock + "\ngit lfs pre-push \"$@\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := chainHookBlock(HookPrePushTemplate, tt.original, block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("chainHookBlock() = %q, want %q", got, tt.want)
+			}
+
+			removed, ok := removeHookBlock(got)
+			if !ok || removed != tt.original {
+				t.Errorf("removeHookBlock() = %q, %v, want %q, true", removed, ok, tt.original)
+			}
+		})
+	}
+}
+
+func TestIsLegacyHook(t *testing.T) {
+	if !isLegacyHook(HookPrepareCommitMessageTemplate, legacyPrepareCommitMessageHook) {
+		t.Error("isLegacyHook() = false for the hook of older versions")
+	}
+	if isLegacyHook(HookPrepareCommitMessageTemplate, "#!/bin/sh\nnpx codegpt commit --file $1 --preview\n") {
+		t.Error("isLegacyHook() = true for a user script calling codegpt")
+	}
+}
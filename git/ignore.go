@@ -0,0 +1,110 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// IgnoreFile lists the files never sent to the model with the gitignore syntax.
+// Like .gitignore, it can be placed in any folder and its patterns are relative to that folder.
+const IgnoreFile = ".codegptignore"
+
+var errAllIgnored = errors.New("all staged changes are ignored by " + IgnoreFile)
+
+// readIgnoreFile reads the patterns of the ignore file in the folder.
+func readIgnoreFile(fs billy.Filesystem, dir string) []gitignore.Pattern {
+	domain := []string{}
+	if dir != "" {
+		domain = strings.Split(dir, "/")
+	}
+
+	f, err := fs.Open(path.Join(dir, IgnoreFile))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	patterns := []gitignore.Pattern{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, domain))
+	}
+
+	return patterns
+}
+
+// ignoredFiles returns the files matching the patterns of the ignore files in the
+// root folder and the folders of the files. Nested files have a higher priority.
+func ignoredFiles(fs billy.Filesystem, names []string) []string {
+	dirs := map[string]bool{"": true}
+	for _, name := range names {
+		for dir := path.Dir(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+	sorted := []string{}
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	depth := func(dir string) int {
+		if dir == "" {
+			return 0
+		}
+		return strings.Count(dir, "/") + 1
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if depth(sorted[i]) != depth(sorted[j]) {
+			return depth(sorted[i]) < depth(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	patterns := []gitignore.Pattern{}
+	for _, dir := range sorted {
+		patterns = append(patterns, readIgnoreFile(fs, dir)...)
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	matcher := gitignore.NewMatcher(patterns)
+	ignored := []string{}
+	for _, name := range names {
+		if matcher.Match(strings.Split(name, "/"), false) {
+			ignored = append(ignored, name)
+		}
+	}
+
+	return ignored
+}
+
+// withoutIgnored splits the files into the kept and the ignored ones.
+func withoutIgnored(fs billy.Filesystem, names []string) ([]string, []string) {
+	ignored := ignoredFiles(fs, names)
+	if len(ignored) == 0 {
+		return names, nil
+	}
+
+	skip := map[string]bool{}
+	for _, name := range ignored {
+		skip[name] = true
+	}
+	kept := []string{}
+	for _, name := range names {
+		if !skip[name] {
+			kept = append(kept, name)
+		}
+	}
+
+	return kept, ignored
+}
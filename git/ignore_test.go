@@ -0,0 +1,35 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+)
+
+func TestIgnoredFiles(t *testing.T) {
+	fs := memfs.New()
+	writeFile(t, fs, IgnoreFile, "# secrets\n*.pem\n/fixtures/\ndocs/internal\n")
+	writeFile(t, fs, "api/"+IgnoreFile, "testdata/\n!keep.pem\n")
+
+	names := []string{
+		"main.go",
+		"certs/server.pem",
+		"fixtures/users.json",
+		"pkg/fixtures/users.json",
+		"docs/internal/plan.md",
+		"docs/guide.md",
+		"api/testdata/response.json",
+		"api/keep.pem",
+		"testdata/golden.txt",
+	}
+	want := []string{
+		"certs/server.pem",
+		"fixtures/users.json",
+		"docs/internal/plan.md",
+		"api/testdata/response.json",
+	}
+	if got := ignoredFiles(fs, names); !reflect.DeepEqual(got, want) {
+		t.Errorf("ignoredFiles() = %v, want %v", got, want)
+	}
+}
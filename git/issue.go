@@ -0,0 +1,33 @@
+package git
+
+import "regexp"
+
+// IssueKeys extracts the ticket IDs like `PROJ-1234` from the branch name using the pattern.
+// If the pattern has a capturing group, the first group is used as the ticket ID.
+// It returns the unique ticket IDs in the order they appear.
+func IssueKeys(branch, pattern string) ([]string, error) {
+	keys := []string{}
+	if pattern == "" || branch == "" {
+		return keys, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, m := range re.FindAllStringSubmatch(branch, -1) {
+		key := m[0]
+		if len(m) > 1 {
+			key = m[1]
+		}
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
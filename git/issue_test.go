@@ -0,0 +1,57 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIssueKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  string
+		pattern string
+		want    []string
+	}{
+		{
+			name:    "feature branch",
+			branch:  "feature/PROJ-1234-add-cache",
+			pattern: `[A-Z][A-Z0-9]+-\d+`,
+			want:    []string{"PROJ-1234"},
+		},
+		{
+			name:    "multiple and duplicate keys",
+			branch:  "fix/PROJ-1-PROJ-2-PROJ-1",
+			pattern: `[A-Z][A-Z0-9]+-\d+`,
+			want:    []string{"PROJ-1", "PROJ-2"},
+		},
+		{
+			name:    "capturing group",
+			branch:  "issue-42-fix-typo",
+			pattern: `issue-(\d+)`,
+			want:    []string{"42"},
+		},
+		{
+			name:    "no match",
+			branch:  "main",
+			pattern: `[A-Z][A-Z0-9]+-\d+`,
+			want:    []string{},
+		},
+		{
+			name:    "empty pattern",
+			branch:  "feature/PROJ-1234-add-cache",
+			pattern: "",
+			want:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IssueKeys(tt.branch, tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("IssueKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -41,9 +41,70 @@ func WithEnableAmend(val bool) Option {
 	})
 }
 
+// WithDiffRange returns an Option that compares the from and to commits instead of the staged changes.
+func WithDiffRange(from, to string) Option {
+	return optionFunc(func(c *config) {
+		c.diffFrom = from
+		c.diffTo = to
+	})
+}
+
+// WithSignoff returns an Option that adds the Signed-off-by trailer to the commit.
+func WithSignoff(val bool) Option {
+	return optionFunc(func(c *config) {
+		c.signoff = val
+	})
+}
+
+// WithGPGSign returns an Option that signs the commit with GPG or SSH.
+// The value is `true` to use the default key, `false` to disable signing, or the key ID.
+func WithGPGSign(val string) Option {
+	return optionFunc(func(c *config) {
+		c.gpgSign = val
+	})
+}
+
+// WithVerify returns an Option that runs the pre-commit and commit-msg hooks when committing.
+func WithVerify(val bool) Option {
+	return optionFunc(func(c *config) {
+		c.verify = val
+	})
+}
+
+// WithAuthor returns an Option that overrides the commit author, e.g. `A U Thor <author@example.com>`.
+func WithAuthor(val string) Option {
+	return optionFunc(func(c *config) {
+		c.author = val
+	})
+}
+
+// WithCoAuthors returns an Option that adds a Co-authored-by trailer to the commit for each co-author.
+func WithCoAuthors(val []string) Option {
+	return optionFunc(func(c *config) {
+		c.coAuthors = val
+	})
+}
+
+// WithIncludeGenerated returns an Option that keeps the content of binary, generated, vendored
+// and minified files in the diff instead of a one-line placeholder.
+func WithIncludeGenerated(val bool) Option {
+	return optionFunc(func(c *config) {
+		c.includeGenerated = val
+	})
+}
+
 // config is a struct that stores configuration options for the instrumentation.
 type config struct {
 	diffUnified int
 	excludeList []string
 	isAmend     bool
+	diffFrom    string
+	diffTo      string
+	signoff     bool
+	gpgSign     string
+	verify      bool
+	author      string
+	coAuthors   []string
+
+	includeGenerated bool
 }
@@ -0,0 +1,127 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// ZeroSHA is the object name git uses for a ref which doesn't exist.
+	ZeroSHA = "0000000000000000000000000000000000000000"
+	// EmptyTreeSHA is the object name of the empty tree, used to diff the root commit.
+	EmptyTreeSHA = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+)
+
+// PushRange is a ref updated by git push, as passed to the pre-push hook on stdin.
+type PushRange struct {
+	LocalRef  string
+	LocalSHA  string
+	RemoteRef string
+	RemoteSHA string
+}
+
+// IsDelete reports whether the push deletes the remote ref.
+func (r PushRange) IsDelete() bool {
+	return r.LocalSHA == ZeroSHA
+}
+
+// ParsePushRanges parses the lines `<local ref> <local sha> <remote ref> <remote sha>`
+// git passes to the pre-push hook.
+func ParsePushRanges(r io.Reader) ([]PushRange, error) {
+	ranges := []PushRange{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("invalid pre-push line: %q", scanner.Text())
+		}
+		ranges = append(ranges, PushRange{
+			LocalRef:  fields[0],
+			LocalSHA:  fields[1],
+			RemoteRef: fields[2],
+			RemoteSHA: fields[3],
+		})
+	}
+
+	return ranges, scanner.Err()
+}
+
+func (c *Command) revList(sha string) *exec.Cmd {
+	args := []string{
+		"rev-list",
+		"--reverse",
+		sha,
+		"--not",
+		"--remotes",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+func (c *Command) catFile(sha string) *exec.Cmd {
+	args := []string{
+		"cat-file",
+		"-e",
+		sha + "^{commit}",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+func (c *Command) mergeBase(a, b string) *exec.Cmd {
+	args := []string{
+		"merge-base",
+		a,
+		b,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// PushBase returns the commit the pushed range starts from. For an existing remote ref, it is
+// the merge base of the remote and local commits, so a force push only reviews the new commits.
+// For a new remote ref, or a remote commit which was never fetched, it is the parent of the
+// oldest commit not on any remote yet, or the empty tree for a root commit.
+// It returns an empty string if there is nothing to review.
+func (c *Command) PushBase(r PushRange) (string, error) {
+	if r.IsDelete() {
+		return "", nil
+	}
+	if r.RemoteSHA != ZeroSHA && c.catFile(r.RemoteSHA).Run() == nil {
+		if output, err := c.mergeBase(r.RemoteSHA, r.LocalSHA).Output(); err == nil {
+			return strings.TrimSpace(string(output)), nil
+		}
+	}
+
+	output, err := c.revList(r.LocalSHA).Output()
+	if err != nil {
+		return "", err
+	}
+	commits := strings.Fields(string(output))
+	if len(commits) == 0 {
+		return "", nil
+	}
+
+	output, err = c.revParse(commits[0] + "^").Output()
+	if err != nil {
+		// the oldest commit is a root commit without parent
+		return EmptyTreeSHA, nil
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
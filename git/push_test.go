@@ -0,0 +1,94 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePushRanges(t *testing.T) {
+	input := "refs/heads/main 67890 refs/heads/main 12345\n" +
+		"\n" +
+		"(delete) " + ZeroSHA + " refs/heads/old 12345\n"
+
+	got, err := ParsePushRanges(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PushRange{
+		{LocalRef: "refs/heads/main", LocalSHA: "67890", RemoteRef: "refs/heads/main", RemoteSHA: "12345"},
+		{LocalRef: "(delete)", LocalSHA: ZeroSHA, RemoteRef: "refs/heads/old", RemoteSHA: "12345"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePushRanges() = %v, want %v", got, want)
+	}
+	if got[0].IsDelete() || !got[1].IsDelete() {
+		t.Errorf("IsDelete() = %v, %v, want false, true", got[0].IsDelete(), got[1].IsDelete())
+	}
+
+	if _, err := ParsePushRanges(strings.NewReader("refs/heads/main 67890\n")); err == nil {
+		t.Error("ParsePushRanges() expected error for invalid line")
+	}
+}
+
+// gitRun runs the git command in the current folder and returns the trimmed output.
+func gitRun(t *testing.T, args ...string) string {
+	t.Helper()
+	args = append([]string{"-c", "user.name=codegpt", "-c", "user.email=codegpt@example.com"}, args...)
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestPushBase(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git command not found")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	// A - B on main, and B' force pushed away from the remote
+	gitRun(t, "init", "-q")
+	gitRun(t, "commit", "-q", "--allow-empty", "-m", "A")
+	a := gitRun(t, "rev-parse", "HEAD")
+	gitRun(t, "commit", "-q", "--allow-empty", "-m", "B")
+	b := gitRun(t, "rev-parse", "HEAD")
+	gitRun(t, "checkout", "-q", "-b", "other", a)
+	gitRun(t, "commit", "-q", "--allow-empty", "-m", "B'")
+	rewritten := gitRun(t, "rev-parse", "HEAD")
+	missing := "1234567890abcdef1234567890abcdef12345678"
+
+	tests := []struct {
+		name string
+		r    PushRange
+		want string
+	}{
+		{"delete", PushRange{LocalSHA: ZeroSHA, RemoteSHA: a}, ""},
+		{"fast forward", PushRange{LocalSHA: b, RemoteSHA: a}, a},
+		{"force push", PushRange{LocalSHA: b, RemoteSHA: rewritten}, a},
+		// no remote in the repository, so every commit is new from the root
+		{"remote commit not fetched", PushRange{LocalSHA: b, RemoteSHA: missing}, EmptyTreeSHA},
+		{"new branch", PushRange{LocalSHA: b, RemoteSHA: ZeroSHA}, EmptyTreeSHA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New().PushBase(tt.r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("PushBase() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
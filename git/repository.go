@@ -0,0 +1,76 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Repository is the git backend used by codegpt to read the changes and record the commit.
+// Command shells out to the git binary and GoGit uses the go-git library.
+type Repository interface {
+	// DiffFiles returns the unified diff of the changes.
+	DiffFiles() (string, error)
+	// DiffNames returns the names of the changed files.
+	DiffNames() ([]string, error)
+	// TopLevel returns the path of the top-level directory of the working tree.
+	TopLevel() (string, error)
+	// HookPath returns the path of the hooks directory.
+	HookPath() (string, error)
+	// InstallHook installs the git hook with the given name, chaining an existing hook script.
+	InstallHook(name string) error
+	// UninstallHook removes the codegpt block from the git hook with the given name.
+	UninstallHook(name string) error
+	// IsHookInstalled reports whether the git hook with the given name is managed by codegpt.
+	IsHookInstalled(name string) (bool, error)
+	// CurrentBranch returns the name of the current branch, or an empty string in detached HEAD state.
+	CurrentBranch() (string, error)
+	// Log returns the last n commit messages of the current branch, excluding merge commits.
+	Log(n int) ([]string, error)
+	// RevParse returns the object name of the revision.
+	RevParse(rev string) (string, error)
+	// Editor returns the editor configured for git.
+	Editor() (string, error)
+	// Commit records the changes with the commit message and returns the output.
+	Commit(val string) (string, error)
+}
+
+var (
+	_ Repository = (*Command)(nil)
+	_ Repository = (*GoGit)(nil)
+)
+
+// Backends are the names of the available git backends.
+const (
+	BackendExec  = "exec"
+	BackendGoGit = "go-git"
+)
+
+// matchPathspec reports whether the file name matches the pathspec like git does without
+// the glob magic: the pattern matches the path itself, a leading directory or a wildcard
+// pattern where `*` also matches `/`.
+func matchPathspec(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if pattern == name || strings.HasPrefix(name, strings.TrimSuffix(pattern, "/")+"/") {
+		return true
+	}
+	if !strings.ContainsAny(pattern, "*?[") {
+		return false
+	}
+
+	expr := strings.Builder{}
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+
+	matched, err := regexp.MatchString(expr.String(), name)
+	return err == nil && matched
+}
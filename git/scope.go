@@ -0,0 +1,64 @@
+package git
+
+import (
+	"path"
+	"strings"
+)
+
+// ScopeRule maps changed paths to a conventional commit scope.
+type ScopeRule struct {
+	// Pattern is a path prefix like `openai/` or a glob like `cmd/*.go`.
+	Pattern string
+	Scope   string
+}
+
+// match reports whether the file matches the rule pattern.
+func (r ScopeRule) match(name string) bool {
+	if strings.HasPrefix(name, r.Pattern) {
+		return true
+	}
+	ok, _ := path.Match(r.Pattern, name)
+	return ok
+}
+
+// ParseScopeRules parses rules written as `pattern=scope`, e.g. `openai/=openai`.
+// Invalid rules are ignored.
+func ParseScopeRules(vals []string) []ScopeRule {
+	rules := []ScopeRule{}
+	for _, val := range vals {
+		pattern, scope, ok := strings.Cut(val, "=")
+		pattern = strings.TrimSpace(pattern)
+		scope = strings.TrimSpace(scope)
+		if !ok || pattern == "" || scope == "" {
+			continue
+		}
+		rules = append(rules, ScopeRule{Pattern: pattern, Scope: scope})
+	}
+	return rules
+}
+
+// DetectScope returns the scope shared by all the changed files matched by the rules.
+// The longest matching pattern wins for every file and unmatched files are ignored.
+// It returns an empty string if no file matches or the files map to different scopes.
+func DetectScope(files []string, rules []ScopeRule) string {
+	scope := ""
+	for _, name := range files {
+		best := -1
+		for i, rule := range rules {
+			if !rule.match(name) {
+				continue
+			}
+			if best == -1 || len(rule.Pattern) > len(rules[best].Pattern) {
+				best = i
+			}
+		}
+		if best == -1 {
+			continue
+		}
+		if scope != "" && scope != rules[best].Scope {
+			return ""
+		}
+		scope = rules[best].Scope
+	}
+	return scope
+}
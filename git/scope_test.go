@@ -0,0 +1,57 @@
+package git
+
+import "testing"
+
+func TestDetectScope(t *testing.T) {
+	rules := ParseScopeRules([]string{
+		"openai/=openai",
+		"cmd/=cli",
+		"cmd/hook.go=hook",
+		"*.md=docs",
+		"invalid",
+	})
+
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{
+			name:  "single scope",
+			files: []string{"openai/openai.go", "openai/options.go"},
+			want:  "openai",
+		},
+		{
+			name:  "longest pattern wins",
+			files: []string{"cmd/hook.go"},
+			want:  "hook",
+		},
+		{
+			name:  "glob pattern",
+			files: []string{"README.md"},
+			want:  "docs",
+		},
+		{
+			name:  "ignore unmatched files",
+			files: []string{"go.mod", "openai/openai.go"},
+			want:  "openai",
+		},
+		{
+			name:  "different scopes",
+			files: []string{"cmd/commit.go", "openai/openai.go"},
+			want:  "",
+		},
+		{
+			name:  "no match",
+			files: []string{"go.mod"},
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectScope(tt.files, rules); got != tt.want {
+				t.Errorf("DetectScope() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	conventionalTitle = regexp.MustCompile(`^\w+(\([^)]*\))?!?: `)
+	gitmojiShortcode  = regexp.MustCompile(`^:\w+:\s*`)
+)
+
+// CommitStyle describes the conventions of the existing commit messages.
+type CommitStyle struct {
+	// Conventional is true if most titles follow the conventional commits specification.
+	Conventional bool
+	// Capitalized is true if most titles start with an uppercase letter,
+	// ignoring the conventional commit prefix and gitmoji.
+	Capitalized bool
+	// Gitmoji is true if most titles start with a gitmoji.
+	Gitmoji bool
+	// TitleLength is the maximum length of the titles,
+	// ignoring the conventional commit prefix and gitmoji.
+	TitleLength int
+	// Titles are the titles without the conventional commit prefix and gitmoji.
+	Titles []string
+}
+
+// stripGitmoji removes the leading gitmoji, either a shortcode like `:sparkles:` or an emoji.
+func stripGitmoji(title string) (string, bool) {
+	if loc := gitmojiShortcode.FindStringIndex(title); loc != nil {
+		return title[loc[1]:], true
+	}
+	r, size := utf8.DecodeRuneInString(title)
+	if r != utf8.RuneError && unicode.Is(unicode.So, r) {
+		// skip the variation selector and spaces following the emoji
+		return strings.TrimLeft(title[size:], "\ufe0f "), true
+	}
+	return title, false
+}
+
+// DetectCommitStyle detects the conventions used by the given commit messages.
+func DetectCommitStyle(messages []string) CommitStyle {
+	style := CommitStyle{
+		Titles: []string{},
+	}
+
+	conventional, capitalized, gitmoji := 0, 0, 0
+	for _, message := range messages {
+		title, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+		title = strings.TrimSpace(title)
+		if title == "" {
+			continue
+		}
+
+		title, ok := stripGitmoji(title)
+		if ok {
+			gitmoji++
+		}
+
+		if loc := conventionalTitle.FindStringIndex(title); loc != nil {
+			conventional++
+			title = title[loc[1]:]
+		}
+
+		if n := utf8.RuneCountInString(title); n > style.TitleLength {
+			style.TitleLength = n
+		}
+
+		r, _ := utf8.DecodeRuneInString(title)
+		if unicode.IsUpper(r) {
+			capitalized++
+		}
+
+		style.Titles = append(style.Titles, title)
+	}
+
+	total := len(style.Titles)
+	style.Conventional = total > 0 && conventional*2 > total
+	style.Capitalized = total > 0 && capitalized*2 > total
+	style.Gitmoji = total > 0 && gitmoji*2 > total
+
+	return style
+}
@@ -0,0 +1,58 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectCommitStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     CommitStyle
+	}{
+		{
+			name: "conventional commits",
+			messages: []string{
+				"feat(openai): add proxy support\n\n- body",
+				"fix: remove last period",
+				"Update README.md",
+			},
+			want: CommitStyle{
+				Conventional: true,
+				Capitalized:  false,
+				Gitmoji:      false,
+				TitleLength:  18,
+				Titles:       []string{"add proxy support", "remove last period", "Update README.md"},
+			},
+		},
+		{
+			name: "gitmoji",
+			messages: []string{
+				":sparkles: Add proxy support",
+				"🐛 Remove last period",
+			},
+			want: CommitStyle{
+				Conventional: false,
+				Capitalized:  true,
+				Gitmoji:      true,
+				TitleLength:  18,
+				Titles:       []string{"Add proxy support", "Remove last period"},
+			},
+		},
+		{
+			name:     "empty history",
+			messages: []string{},
+			want: CommitStyle{
+				Titles: []string{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectCommitStyle(tt.messages); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectCommitStyle() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
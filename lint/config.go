@@ -0,0 +1,229 @@
+package lint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/appleboy/com/file"
+	"github.com/spf13/viper"
+)
+
+// ConfigFiles are the commitlint config file names, in the order they are searched.
+var ConfigFiles = []string{
+	".commitlintrc",
+	".commitlintrc.json",
+	".commitlintrc.yaml",
+	".commitlintrc.yml",
+	".commitlintrc.js",
+	".commitlintrc.cjs",
+	"commitlint.config.js",
+	"commitlint.config.cjs",
+}
+
+// FindConfig returns the path of the first commitlint config file found in the folder,
+// or an empty string.
+func FindConfig(folder string) string {
+	for _, name := range ConfigFiles {
+		if target := filepath.Join(folder, name); file.IsFile(target) {
+			return target
+		}
+	}
+	return ""
+}
+
+// stripJSComments removes the `//` line and `/* */` block comments outside of the strings.
+func stripJSComments(src string) string {
+	out := strings.Builder{}
+	for i := 0; i < len(src); i++ {
+		switch {
+		case src[i] == '\'' || src[i] == '"' || src[i] == '`':
+			end := stringEnd(src, i)
+			out.WriteString(src[i:end])
+			i = end - 1
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			out.WriteByte('\n')
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end == -1 {
+				return out.String()
+			}
+			i += end + 3
+		default:
+			out.WriteByte(src[i])
+		}
+	}
+	return out.String()
+}
+
+// stringEnd returns the index after the closing quote of the string starting at i.
+func stringEnd(src string, i int) int {
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case src[i]:
+			return j + 1
+		}
+	}
+	return len(src)
+}
+
+// nextToken returns the first character after the spaces starting at i, or 0 at the end.
+func nextToken(src string, i int) byte {
+	for ; i < len(src); i++ {
+		if !strings.ContainsRune(" \t\r\n", rune(src[i])) {
+			return src[i]
+		}
+	}
+	return 0
+}
+
+func isIdentifier(c byte, first bool) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || !first && c >= '0' && c <= '9'
+}
+
+// jsToJSON converts the object exported by a JavaScript config like
+// `module.exports = { extends: ['...'], rules: { ... } }` into JSON.
+// Comments, single quotes, unquoted keys, trailing commas and `Infinity` are supported,
+// but only plain object literals.
+func jsToJSON(content []byte) ([]byte, error) {
+	src := stripJSComments(string(content))
+	start := strings.Index(src, "{")
+	end := strings.LastIndex(src, "}")
+	if start == -1 || end < start {
+		return nil, errors.New("can't find the exported config object")
+	}
+	src = src[start : end+1]
+
+	out := strings.Builder{}
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '"':
+			end := stringEnd(src, i)
+			out.WriteString(src[i:end])
+			i = end - 1
+		case c == '\'' || c == '`':
+			end := stringEnd(src, i)
+			val := strings.TrimSuffix(src[i+1:end], string(c))
+			fmt.Fprintf(&out, "%q", strings.ReplaceAll(val, `\`+string(c), string(c)))
+			i = end - 1
+		case c >= '0' && c <= '9':
+			// keep numbers like 1e3 out of the identifiers
+			j := i + 1
+			for j < len(src) && (isIdentifier(src[j], false) || src[j] == '.') {
+				j++
+			}
+			out.WriteString(src[i:j])
+			i = j - 1
+		case isIdentifier(c, true):
+			j := i + 1
+			for j < len(src) && isIdentifier(src[j], false) {
+				j++
+			}
+			word := src[i:j]
+			switch {
+			case nextToken(src, j) == ':':
+				fmt.Fprintf(&out, "%q", word)
+			case word == "Infinity":
+				// the rules read `Infinity` like the string
+				out.WriteString(`"Infinity"`)
+			case word == "true", word == "false", word == "null":
+				out.WriteString(word)
+			case word == "undefined":
+				out.WriteString("null")
+			default:
+				return nil, fmt.Errorf("unsupported expression %s, only plain object literals are supported", word)
+			}
+			i = j - 1
+		case c == ',':
+			// JSON doesn't allow trailing commas
+			if next := nextToken(src, i+1); next != '}' && next != ']' {
+				out.WriteByte(c)
+			}
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return []byte(out.String()), nil
+}
+
+// LoadConfig reads the rules from the commitlint config file.
+// Configs extending @commitlint/config-conventional start from the default rules.
+func LoadConfig(target string) (Rules, error) {
+	content, err := os.ReadFile(target)
+	if err != nil {
+		return nil, err
+	}
+
+	configType := strings.TrimPrefix(filepath.Ext(target), ".")
+	switch configType {
+	case "js", "cjs":
+		content, err = jsToJSON(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", target, err)
+		}
+		configType = "json"
+	case "", "commitlintrc":
+		// .commitlintrc is either JSON or YAML, and JSON is valid YAML
+		configType = "yaml"
+	}
+
+	v := viper.New()
+	v.SetConfigType(configType)
+	if err := v.ReadConfig(bytes.NewReader(content)); err != nil {
+		return nil, fmt.Errorf("%s: %w", target, err)
+	}
+
+	rules := Rules{}
+	for _, extend := range v.GetStringSlice("extends") {
+		if strings.Contains(extend, "config-conventional") {
+			rules = DefaultRules()
+		}
+	}
+
+	raw, ok := v.Get("rules").(map[string]interface{})
+	if !ok {
+		return rules, nil
+	}
+	for name, val := range raw {
+		rule, err := parseRule(val)
+		if err != nil {
+			return nil, fmt.Errorf("%s: rule %s: %w", target, name, err)
+		}
+		rules[name] = rule
+	}
+
+	return rules, nil
+}
+
+// parseRule parses the rule configuration like `[2, 'always', 100]`.
+func parseRule(val interface{}) (Rule, error) {
+	items, ok := val.([]interface{})
+	if !ok || len(items) == 0 {
+		return Rule{}, errors.New("rule must be an array like [2, 'always', 100]")
+	}
+
+	rule := Rule{
+		Level:      Level(toInt(items[0])),
+		Applicable: "always",
+	}
+	if rule.Level < Disabled || rule.Level > Error {
+		return Rule{}, fmt.Errorf("invalid level %v", items[0])
+	}
+	if len(items) > 1 {
+		rule.Applicable = fmt.Sprint(items[1])
+	}
+	if len(items) > 2 {
+		rule.Value = items[2]
+	}
+
+	return rule, nil
+}
@@ -0,0 +1,405 @@
+package lint
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/appleboy/com/array"
+)
+
+// Level is the severity of a rule.
+type Level int
+
+const (
+	// Disabled rules are not checked.
+	Disabled Level = iota
+	// Warning violations are reported but don't fail the lint.
+	Warning
+	// Error violations fail the lint.
+	Error
+)
+
+func (l Level) String() string {
+	switch l {
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return "disabled"
+}
+
+// Rule is a commitlint rule configuration like `[2, 'always', 100]`.
+type Rule struct {
+	Level Level
+	// Applicable is either `always` or `never`.
+	Applicable string
+	Value      interface{}
+}
+
+// Rules maps rule names like `header-max-length` to their configuration.
+type Rules map[string]Rule
+
+// Violation is a rule violated by a commit message.
+type Violation struct {
+	Rule    string
+	Level   Level
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s [%s]", v.Level, v.Message, v.Rule)
+}
+
+// HasErrors reports whether any violation has the error level.
+func HasErrors(violations []Violation) bool {
+	for _, v := range violations {
+		if v.Level == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRules returns the rules of @commitlint/config-conventional.
+func DefaultRules() Rules {
+	return Rules{
+		"body-leading-blank":     {Warning, "always", nil},
+		"body-max-line-length":   {Error, "always", 100},
+		"footer-leading-blank":   {Warning, "always", nil},
+		"footer-max-line-length": {Error, "always", 100},
+		"header-max-length":      {Error, "always", 100},
+		"subject-case": {Error, "never", []interface{}{
+			"sentence-case", "start-case", "pascal-case", "upper-case",
+		}},
+		"subject-empty":     {Error, "never", nil},
+		"subject-full-stop": {Error, "never", "."},
+		"type-case":         {Error, "always", "lower-case"},
+		"type-empty":        {Error, "never", nil},
+		"type-enum": {Error, "always", []interface{}{
+			"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test",
+		}},
+	}
+}
+
+var (
+	gitmojiShortcode = regexp.MustCompile(`^:\w+:\s*`)
+	headerPattern    = regexp.MustCompile(`^(\w*)(?:\(([^)]*)\))?!?: (.*)$`)
+	ignorePattern    = regexp.MustCompile(`^(Merge |Revert |Reapply |fixup! |squash! |amend! |Initial commit$|Automatic merge)`)
+	trailerPattern   = regexp.MustCompile(`^(BREAKING CHANGE|BREAKING-CHANGE|[\w-]+)(: | #)`)
+	kebabCase        = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	snakeCase        = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+	pascalCase       = regexp.MustCompile(`^[A-Z][a-z0-9]+([A-Z][a-z0-9]+)*$`)
+	quoted           = regexp.MustCompile("`.*?`|\".*?\"|'.*?'")
+)
+
+// message is a commit message split into its parts.
+type message struct {
+	header  string
+	typ     string
+	scope   string
+	subject string
+	// body and footer lines, footer is the trailing block of trailers like `Refs: PROJ-1`
+	body   []string
+	footer []string
+	// bodyLeadingBlank and footerLeadingBlank are false if the part doesn't follow a blank line
+	bodyLeadingBlank   bool
+	footerLeadingBlank bool
+}
+
+func parse(raw string) message {
+	lines := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n") {
+		// everything below the scissors line of `git commit --verbose` is the diff
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		// skip git comments
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+
+	m := message{bodyLeadingBlank: true, footerLeadingBlank: true}
+	if len(lines) == 0 {
+		return m
+	}
+
+	m.header = lines[0]
+	if match := headerPattern.FindStringSubmatch(stripGitmoji(m.header)); match != nil {
+		m.typ, m.scope, m.subject = match[1], match[2], match[3]
+	}
+
+	rest := lines[1:]
+	footerStart := len(rest)
+	for i := len(rest) - 1; i >= 0 && trailerPattern.MatchString(rest[i]); i-- {
+		footerStart = i
+	}
+	if footerStart < len(rest) {
+		m.footer = rest[footerStart:]
+		m.footerLeadingBlank = footerStart > 0 && rest[footerStart-1] == ""
+		rest = rest[:footerStart]
+	}
+	if len(rest) > 0 {
+		m.bodyLeadingBlank = rest[0] == ""
+		for len(rest) > 0 && rest[0] == "" {
+			rest = rest[1:]
+		}
+		for len(rest) > 0 && rest[len(rest)-1] == "" {
+			rest = rest[:len(rest)-1]
+		}
+		m.body = rest
+	}
+
+	return m
+}
+
+// stripGitmoji removes the leading gitmoji of the header, either a shortcode like `:sparkles:`
+// or an emoji, so `✨ feat(git): add cache` is parsed like `feat(git): add cache`.
+func stripGitmoji(header string) string {
+	if loc := gitmojiShortcode.FindStringIndex(header); loc != nil {
+		return header[loc[1]:]
+	}
+	r, size := utf8.DecodeRuneInString(header)
+	if r != utf8.RuneError && unicode.Is(unicode.So, r) {
+		// skip the variation selector and spaces following the emoji
+		return strings.TrimLeft(header[size:], "\ufe0f ")
+	}
+	return header
+}
+
+// toInt converts the rule value to a number, `Infinity` is the maximum int.
+func toInt(val interface{}) int {
+	switch v := val.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case string:
+		if strings.EqualFold(v, "infinity") {
+			return math.MaxInt
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return math.MaxInt
+		}
+		return n
+	}
+	return math.MaxInt
+}
+
+// toStrings converts the rule value to a list of strings.
+func toStrings(val interface{}) []string {
+	switch v := val.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		items := []string{}
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return items
+	}
+	return nil
+}
+
+// titleCase returns the word with the first letter in upper case and the rest in lower case.
+func titleCase(word string) string {
+	first, size := utf8.DecodeRuneInString(word)
+	if first == utf8.RuneError {
+		return word
+	}
+	return string(unicode.ToUpper(first)) + strings.ToLower(word[size:])
+}
+
+// isCase reports whether the value is written in the case like `lower-case` or `sentence-case`.
+// Like commitlint, the value matches the case if it equals the value converted to the case,
+// so `HTTP proxy` or `README typo` are not sentence-case, and quoted parts are ignored.
+func isCase(val, c string) bool {
+	val = strings.TrimSpace(quoted.ReplaceAllString(val, ""))
+	first, _ := utf8.DecodeRuneInString(val)
+	words := strings.Fields(val)
+	switch c {
+	case "lower-case", "lowercase":
+		return val == strings.ToLower(val)
+	case "upper-case", "uppercase":
+		return val == strings.ToUpper(val)
+	case "sentence-case", "sentencecase":
+		return val != "" && val == titleCase(val)
+	case "start-case", "startcase":
+		for _, w := range words {
+			if w != titleCase(w) {
+				return false
+			}
+		}
+		return len(words) > 0
+	case "pascal-case", "pascalcase":
+		return pascalCase.MatchString(val)
+	case "camel-case", "camelcase":
+		return unicode.IsLower(first) && len(words) == 1 && val != strings.ToLower(val) &&
+			!strings.ContainsAny(val, "-_")
+	case "kebab-case", "kebabcase":
+		return kebabCase.MatchString(val)
+	case "snake-case", "snakecase":
+		return snakeCase.MatchString(val)
+	}
+	return false
+}
+
+// check applies the `always` or `never` condition to the rule result.
+func (r Rule) check(ok bool) bool {
+	if r.Applicable == "never" {
+		return !ok
+	}
+	return ok
+}
+
+// must returns `must` or `must not` for the rule.
+func (r Rule) must() string {
+	if r.Applicable == "never" {
+		return "must not"
+	}
+	return "must"
+}
+
+// Lint validates the commit message against the rules and returns the violations.
+func Lint(raw string, rules Rules) []Violation {
+	m := parse(raw)
+	violations := []Violation{}
+	// commits created by git itself like merges, reverts and fixups are not linted
+	if ignorePattern.MatchString(m.header) {
+		return violations
+	}
+	report := func(name string, rule Rule, format string, args ...interface{}) {
+		violations = append(violations, Violation{
+			Rule:    name,
+			Level:   rule.Level,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	for _, name := range ruleNames {
+		rule, ok := rules[name]
+		if !ok || rule.Level == Disabled {
+			continue
+		}
+
+		switch name {
+		case "header-max-length":
+			if n := toInt(rule.Value); utf8.RuneCountInString(m.header) > n {
+				report(name, rule, "header must not be longer than %d characters, current length is %d", n, utf8.RuneCountInString(m.header))
+			}
+		case "header-min-length":
+			if n := toInt(rule.Value); n != math.MaxInt && utf8.RuneCountInString(m.header) < n {
+				report(name, rule, "header must not be shorter than %d characters, current length is %d", n, utf8.RuneCountInString(m.header))
+			}
+		case "type-empty":
+			if !rule.check(m.typ == "") {
+				report(name, rule, "type %s be empty", rule.must())
+			}
+		case "type-enum":
+			if m.typ != "" && !rule.check(array.InSlice(m.typ, toStrings(rule.Value))) {
+				report(name, rule, "type %s be one of [%s]", rule.must(), strings.Join(toStrings(rule.Value), ", "))
+			}
+		case "type-case":
+			if m.typ != "" && !rule.check(anyCase(m.typ, toStrings(rule.Value))) {
+				report(name, rule, "type %s be %s", rule.must(), strings.Join(toStrings(rule.Value), ", "))
+			}
+		case "scope-enum":
+			if m.scope != "" && !rule.check(array.InSlice(m.scope, toStrings(rule.Value))) {
+				report(name, rule, "scope %s be one of [%s]", rule.must(), strings.Join(toStrings(rule.Value), ", "))
+			}
+		case "scope-case":
+			if m.scope != "" && !rule.check(anyCase(m.scope, toStrings(rule.Value))) {
+				report(name, rule, "scope %s be %s", rule.must(), strings.Join(toStrings(rule.Value), ", "))
+			}
+		case "subject-empty":
+			if !rule.check(m.subject == "") {
+				report(name, rule, "subject %s be empty", rule.must())
+			}
+		case "subject-case":
+			if m.subject != "" && !rule.check(anyCase(m.subject, toStrings(rule.Value))) {
+				report(name, rule, "subject %s be %s", rule.must(), strings.Join(toStrings(rule.Value), ", "))
+			}
+		case "subject-full-stop":
+			stop := "."
+			if v := toStrings(rule.Value); len(v) > 0 {
+				stop = v[0]
+			}
+			if m.subject != "" && !rule.check(strings.HasSuffix(m.subject, stop)) {
+				report(name, rule, "subject %s end with full stop %q", rule.must(), stop)
+			}
+		case "body-leading-blank":
+			if len(m.body) > 0 && !rule.check(m.bodyLeadingBlank) {
+				report(name, rule, "body %s have leading blank line", rule.must())
+			}
+		case "body-max-line-length":
+			if n := toInt(rule.Value); maxLineLength(m.body) > n {
+				report(name, rule, "body's lines must not be longer than %d characters", n)
+			}
+		case "footer-leading-blank":
+			if len(m.footer) > 0 && !rule.check(m.footerLeadingBlank) {
+				report(name, rule, "footer %s have leading blank line", rule.must())
+			}
+		case "footer-max-line-length":
+			if n := toInt(rule.Value); maxLineLength(m.footer) > n {
+				report(name, rule, "footer's lines must not be longer than %d characters", n)
+			}
+		}
+	}
+
+	return violations
+}
+
+// ruleNames are the supported rules in the order they are checked.
+var ruleNames = []string{
+	"header-max-length",
+	"header-min-length",
+	"type-empty",
+	"type-enum",
+	"type-case",
+	"scope-enum",
+	"scope-case",
+	"subject-empty",
+	"subject-case",
+	"subject-full-stop",
+	"body-leading-blank",
+	"body-max-line-length",
+	"footer-leading-blank",
+	"footer-max-line-length",
+}
+
+func anyCase(val string, cases []string) bool {
+	for _, c := range cases {
+		if isCase(val, c) {
+			return true
+		}
+	}
+	return false
+}
+
+func maxLineLength(lines []string) int {
+	n := 0
+	for _, line := range lines {
+		if l := utf8.RuneCountInString(line); l > n {
+			n = l
+		}
+	}
+	return n
+}
@@ -0,0 +1,188 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []string
+	}{
+		{
+			name:    "valid message",
+			message: "feat(openai): add proxy support\n\n- Add proxy option\n\nRefs: PROJ-1234\n",
+			want:    []string{},
+		},
+		{
+			name:    "invalid type and full stop",
+			message: "feature: add proxy support.",
+			want:    []string{"type-enum", "subject-full-stop"},
+		},
+		{
+			name:    "subject case",
+			message: "fix: Remove last period",
+			want:    []string{"subject-case"},
+		},
+		{
+			name:    "subject starting with an acronym",
+			message: "fix: HTTP proxy support",
+			want:    []string{},
+		},
+		{
+			name:    "subject starting with a file name",
+			message: "fix: README typo",
+			want:    []string{},
+		},
+		{
+			name:    "subject starting with a ticket ID",
+			message: "feat: PROJ-1234 add cache",
+			want:    []string{},
+		},
+		{
+			name:    "start case subject",
+			message: "fix: Remove Last Period",
+			want:    []string{"subject-case"},
+		},
+		{
+			name:    "pascal case subject",
+			message: "fix: RemovePeriod",
+			want:    []string{"subject-case"},
+		},
+		{
+			name:    "upper case subject",
+			message: "fix: REMOVE LAST PERIOD",
+			want:    []string{"subject-case"},
+		},
+		{
+			name:    "gitmoji",
+			message: "✨ feat(git): add cache",
+			want:    []string{},
+		},
+		{
+			name:    "gitmoji shortcode",
+			message: ":bug: fix: remove last period",
+			want:    []string{},
+		},
+		{
+			name:    "missing type",
+			message: "Remove last period",
+			want:    []string{"type-empty", "subject-empty"},
+		},
+		{
+			name:    "leading blank lines",
+			message: "fix: remove last period\n- body\nRefs: PROJ-1234",
+			want:    []string{"body-leading-blank", "footer-leading-blank"},
+		},
+		{
+			name:    "ignore merge and fixup commits",
+			message: "Merge branch 'main' into feature\n\nfixup! Remove last period.",
+			want:    []string{},
+		},
+		{
+			name:    "ignore verbose diff",
+			message: "fix: remove last period\n# ------------------------ >8 ------------------------\ndiff --git a/main.go b/main.go\n+ Add Proxy.",
+			want:    []string{},
+		},
+		{
+			name:    "ignore git comments",
+			message: "fix: remove last period\n# Please enter the commit message for your changes.",
+			want:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, v := range Lint(tt.message, DefaultRules()) {
+				got = append(got, v.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	content := `module.exports = {
+  extends: ['@commitlint/config-conventional'],
+  rules: {
+    // allow long lines
+    'body-max-line-length': [0, 'always', Infinity],
+    /* max length of the header */
+    'header-max-length': [2, 'always', 72],
+  },
+};
+`
+	if err := os.WriteFile(filepath.Join(dir, "commitlint.config.js"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	target := FindConfig(dir)
+	if target != filepath.Join(dir, "commitlint.config.js") {
+		t.Fatalf("FindConfig() = %q", target)
+	}
+
+	rules, err := LoadConfig(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules["body-max-line-length"].Level != Disabled {
+		t.Errorf("body-max-line-length level = %v, want disabled", rules["body-max-line-length"].Level)
+	}
+	if rules["header-max-length"].Value != float64(72) {
+		t.Errorf("header-max-length value = %v, want 72", rules["header-max-length"].Value)
+	}
+	if _, ok := rules["type-enum"]; !ok {
+		t.Error("type-enum rule from config-conventional is missing")
+	}
+}
+
+func TestJSToJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			"infinity",
+			`module.exports = { rules: { 'body-max-line-length': [2, 'always', Infinity] } };`,
+			`{ "rules": { "body-max-line-length": [2, "always", "Infinity"] } }`,
+		},
+		{
+			"block and line comments",
+			"/* eslint-disable */\nmodule.exports = {\n  /* the\n  types */ rules: {}, // no rules\n};",
+			"{\n   \"rules\": {} \n}",
+		},
+		{
+			"unquoted keys and trailing commas",
+			"export default {\n  extends: ['@commitlint/config-conventional',],\n  helpUrl: 'https://example.com/commits',\n  defaultIgnores: true,\n};",
+			"{\n  \"extends\": [\"@commitlint/config-conventional\"],\n  \"helpUrl\": \"https://example.com/commits\",\n  \"defaultIgnores\": true\n}",
+		},
+		{
+			"quotes and numbers",
+			`module.exports = { rules: { "subject-case": [1e0, 'never', ['it\'s']] } }`,
+			`{ "rules": { "subject-case": [1e0, "never", ["it's"]] } }`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsToJSON([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("jsToJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := jsToJSON([]byte(`module.exports = { rules: require('./rules') }`)); err == nil {
+		t.Error("jsToJSON() error = nil, want an error for the expressions")
+	}
+}
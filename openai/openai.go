@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
 	openai "github.com/sashabaranov/go-openai"
 	"golang.org/x/net/proxy"
@@ -46,6 +47,22 @@ func GetModel(model string) string {
 	return v
 }
 
+// IsValidModel reports whether the given model name is supported.
+func IsValidModel(model string) bool {
+	_, ok := modelMaps[model]
+	return ok
+}
+
+// Message is a chat message with a role like system, user or assistant.
+type Message = openai.ChatCompletionMessage
+
+// Chat message roles.
+const (
+	RoleSystem    = openai.ChatMessageRoleSystem
+	RoleUser      = openai.ChatMessageRoleUser
+	RoleAssistant = openai.ChatMessageRoleAssistant
+)
+
 // Client is a struct that represents an OpenAI client.
 type Client struct {
 	client      *openai.Client
@@ -56,45 +73,54 @@ type Client struct {
 
 type Response struct {
 	Content string
+	// Choices contains the content of every generated choice, Content is the first one.
+	Choices []string
 	Usage   openai.Usage
 }
 
 // CreateChatCompletion is an API call to create a completion for a chat message.
+// The messages are the conversation so far and n is how many chat completion choices to generate.
 func (c *Client) CreateChatCompletion(
 	ctx context.Context,
-	content string,
+	messages []Message,
+	n int,
 ) (resp openai.ChatCompletionResponse, err error) {
 	req := openai.ChatCompletionRequest{
 		Model:       c.model,
 		MaxTokens:   c.maxTokens,
 		Temperature: c.temperature,
 		TopP:        1,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: content,
-			},
-		},
+		N:           n,
+		Messages:    messages,
 	}
 
 	return c.client.CreateChatCompletion(ctx, req)
 }
 
+// ListModels lists the currently available models,
+// and provides basic information about each one such as the owner and availability.
+func (c *Client) ListModels(ctx context.Context) (openai.ModelsList, error) {
+	return c.client.ListModels(ctx)
+}
+
 // CreateCompletion is an API call to create a completion.
 // This is the main endpoint of the API. It returns new text, as well as, if requested,
 // the probabilities over each alternative token at each position.
 //
 // If using a fine-tuned model, simply provide the model's ID in the CompletionRequest object,
 // and the server will use the model's parameters to generate the completion.
+// n is how many completions to generate.
 func (c *Client) CreateCompletion(
 	ctx context.Context,
 	content string,
+	n int,
 ) (resp openai.CompletionResponse, err error) {
 	req := openai.CompletionRequest{
 		Model:       c.model,
 		MaxTokens:   c.maxTokens,
 		Temperature: c.temperature,
 		TopP:        1,
+		N:           n,
 		Prompt:      content,
 	}
 
@@ -107,6 +133,34 @@ func (c *Client) Completion(
 	ctx context.Context,
 	content string,
 ) (*Response, error) {
+	return c.Completions(ctx, content, 1)
+}
+
+// Completions is like Completion but generates n choices, which are returned in Response.Choices.
+func (c *Client) Completions(
+	ctx context.Context,
+	content string,
+	n int,
+) (*Response, error) {
+	return c.Chat(ctx, []Message{
+		{
+			Role:    RoleUser,
+			Content: content,
+		},
+	}, n)
+}
+
+// Chat generates n choices for the next assistant message of a multi-message conversation.
+// Models without chat support get the content of all messages as a single prompt.
+func (c *Client) Chat(
+	ctx context.Context,
+	messages []Message,
+	n int,
+) (*Response, error) {
+	if n < 1 {
+		n = 1
+	}
+
 	resp := &Response{}
 	switch c.model {
 	case openai.GPT3Dot5Turbo,
@@ -115,20 +169,32 @@ func (c *Client) Completion(
 		openai.GPT432K,
 		openai.GPT40314,
 		openai.GPT4:
-		r, err := c.CreateChatCompletion(ctx, content)
+		r, err := c.CreateChatCompletion(ctx, messages, n)
 		if err != nil {
 			return nil, err
 		}
-		resp.Content = r.Choices[0].Message.Content
+		for _, choice := range r.Choices {
+			resp.Choices = append(resp.Choices, choice.Message.Content)
+		}
 		resp.Usage = r.Usage
 	default:
-		r, err := c.CreateCompletion(ctx, content)
+		contents := []string{}
+		for _, m := range messages {
+			contents = append(contents, m.Content)
+		}
+		r, err := c.CreateCompletion(ctx, strings.Join(contents, "\n\n"), n)
 		if err != nil {
 			return nil, err
 		}
-		resp.Content = r.Choices[0].Text
+		for _, choice := range r.Choices {
+			resp.Choices = append(resp.Choices, choice.Text)
+		}
 		resp.Usage = r.Usage
 	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("no choices returned from the API")
+	}
+	resp.Content = resp.Choices[0]
 	return resp, nil
 }
 
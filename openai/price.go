@@ -0,0 +1,87 @@
+package openai
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Price is the price of a model in USD per 1K tokens.
+type Price struct {
+	Prompt     float64
+	Completion float64
+}
+
+// Cost returns the cost in USD of the prompt and completion tokens.
+func (p Price) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.Prompt + float64(completionTokens)*p.Completion) / 1000
+}
+
+// Prices maps the model names to their price, see https://openai.com/pricing
+var Prices = map[string]Price{
+	"gpt-4-32k-0314":        {0.06, 0.12},
+	"gpt-4-32k":             {0.06, 0.12},
+	"gpt-4-0314":            {0.03, 0.06},
+	"gpt-4":                 {0.03, 0.06},
+	"gpt-3.5-turbo":         {0.0015, 0.002},
+	"gpt-3.5-turbo-0301":    {0.0015, 0.002},
+	"text-davinci-003":      {0.02, 0.02},
+	"text-davinci-002":      {0.02, 0.02},
+	"text-davinci-001":      {0.02, 0.02},
+	"text-curie-001":        {0.002, 0.002},
+	"text-babbage-001":      {0.0005, 0.0005},
+	"text-ada-001":          {0.0004, 0.0004},
+	"davinci-instruct-beta": {0.02, 0.02},
+	"davinci":               {0.02, 0.02},
+	"curie-instruct-beta":   {0.002, 0.002},
+	"curie":                 {0.002, 0.002},
+	"ada":                   {0.0004, 0.0004},
+	"babbage":               {0.0005, 0.0005},
+}
+
+// EstimateTokens estimates the prompt tokens of the messages without a tokenizer:
+// about 4 characters per token for ASCII text, a token per character otherwise,
+// and the few tokens every message adds for its role.
+func EstimateTokens(messages []Message) int {
+	tokens := 3
+	for _, m := range messages {
+		ascii, other := 0, 0
+		for _, r := range m.Content {
+			if r < utf8.RuneSelf {
+				ascii++
+			} else {
+				other++
+			}
+		}
+		tokens += 4 + (ascii+3)/4 + other
+	}
+	return tokens
+}
+
+// ParsePrices parses the prices written as `model=prompt/completion` in USD per 1K tokens,
+// e.g. `gpt-4=0.03/0.06`. A single price is used for both the prompt and the completion.
+func ParsePrices(vals []string) (map[string]Price, error) {
+	prices := map[string]Price{}
+	for _, val := range vals {
+		model, price, ok := strings.Cut(val, "=")
+		model = strings.TrimSpace(model)
+		if !ok || model == "" {
+			return nil, fmt.Errorf("invalid price %q, expected model=prompt/completion", val)
+		}
+		promptPrice, completionPrice, ok := strings.Cut(price, "/")
+		if !ok {
+			completionPrice = promptPrice
+		}
+		p, err := strconv.ParseFloat(strings.TrimSpace(promptPrice), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prompt price of %s: %w", model, err)
+		}
+		c, err := strconv.ParseFloat(strings.TrimSpace(completionPrice), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid completion price of %s: %w", model, err)
+		}
+		prices[model] = Price{Prompt: p, Completion: c}
+	}
+	return prices, nil
+}
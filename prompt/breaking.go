@@ -0,0 +1,20 @@
+package prompt
+
+import (
+	"regexp"
+	"strings"
+)
+
+var breakingPattern = regexp.MustCompile(`(?is)^[\s*_#>` + "`" + `'"-]*(yes|no)\b[\s*_` + "`" + `'".,:-]*(.*)$`)
+
+// ParseBreakingChange returns the description of the breaking change answered as
+// `yes: <description>`, or an empty string if the answer is `no` or doesn't follow the format,
+// so a chatty denial like `No breaking changes.` never adds a BREAKING CHANGE footer.
+func ParseBreakingChange(answer string) string {
+	match := breakingPattern.FindStringSubmatch(strings.TrimSpace(answer))
+	if match == nil || strings.ToLower(match[1]) != "yes" {
+		return ""
+	}
+
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(match[2]), "`'\""))
+}
@@ -0,0 +1,29 @@
+package prompt
+
+import "testing"
+
+func TestParseBreakingChange(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   string
+	}{
+		{"no", ""},
+		{"No.", ""},
+		{"`no`", ""},
+		{"No, this is not a breaking change.", ""},
+		{"No breaking changes.", ""},
+		{"Nothing breaks, the API is the same.", ""},
+		{"The change is not breaking.", ""},
+		{"yes: rename the --file flag to --output", "rename the --file flag to --output"},
+		{"YES - remove the GetModel function, use Model instead", "remove the GetModel function, use Model instead"},
+		{"**Yes**: drop the openai.proxy config", "drop the openai.proxy config"},
+		{"yes", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.answer, func(t *testing.T) {
+			if got := ParseBreakingChange(tt.answer); got != tt.want {
+				t.Errorf("ParseBreakingChange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package prompt
+
+import "testing"
+
+func TestFindGitmoji(t *testing.T) {
+	tests := []struct {
+		name string
+		val  string
+		want string
+	}{
+		{
+			name: "emoji",
+			val:  "✨",
+			want: "✨",
+		},
+		{
+			name: "emoji without variation selector",
+			val:  "⚡ performance",
+			want: "⚡️",
+		},
+		{
+			name: "code",
+			val:  " :bug:\n",
+			want: "🐛",
+		},
+		{
+			name: "unknown",
+			val:  "feat",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindGitmoji(tt.val); got != tt.want {
+				t.Errorf("FindGitmoji() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package prompt
 
 import (
 	"embed"
+	"io/fs"
 	"log"
 
 	"github.com/appleboy/CodeGPT/util"
@@ -15,6 +16,11 @@ const (
 	SummarizeFileDiffTemplate  = "summarize_file_diff.tmpl"
 	SummarizeTitleTemplate     = "summarize_title.tmpl"
 	ConventionalCommitTemplate = "conventional_commit.tmpl"
+	ConventionalScopeTemplate  = "conventional_scope.tmpl"
+	BreakingChangeTemplate     = "breaking_change.tmpl"
+	GitmojiTemplate            = "gitmoji.tmpl"
+	CommitFeedbackTemplate     = "commit_feedback.tmpl"
+	CommitLintTemplate         = "commit_lint.tmpl"
 	TranslationTemplate        = "translation.tmpl"
 )
 
@@ -23,3 +29,25 @@ func init() {
 		log.Fatal(err)
 	}
 }
+
+// Templates returns the names of all the built-in prompt templates.
+func Templates() ([]string, error) {
+	entries, err := fs.ReadDir(files, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	return names, nil
+}
+
+// GetRawTemplate returns the unparsed content of the built-in prompt template.
+func GetRawTemplate(name string) ([]byte, error) {
+	return files.ReadFile("templates/" + name)
+}
@@ -0,0 +1,45 @@
+package prompt
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/appleboy/com/array"
+)
+
+// Severities are the levels of the issues reported by the code review, from the lowest to the highest.
+var Severities = []string{"none", "low", "medium", "high"}
+
+var severityPattern = regexp.MustCompile(`(?im)^[\s*_#>-]*severity[\s*_]*:[\s*_]*(none|low|medium|high)\b.*$`)
+
+// ParseSeverity returns the severity line reported at the end of the code review in lower case,
+// and the review without that line. It returns an empty severity if the review doesn't report one.
+func ParseSeverity(review string) (string, string) {
+	matches := severityPattern.FindAllStringSubmatchIndex(review, -1)
+	if len(matches) == 0 {
+		return "", review
+	}
+
+	last := matches[len(matches)-1]
+	severity := strings.ToLower(review[last[2]:last[3]])
+	return severity, strings.TrimSpace(review[:last[0]] + review[last[1]:])
+}
+
+// SeverityAtLeast reports whether the severity is at least as high as the threshold.
+// Unknown severities never reach the threshold.
+func SeverityAtLeast(severity, threshold string) bool {
+	if !array.InSlice(severity, Severities) || !array.InSlice(threshold, Severities) {
+		return false
+	}
+
+	return indexOf(severity) >= indexOf(threshold)
+}
+
+func indexOf(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,48 @@
+package prompt
+
+import "testing"
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		name     string
+		review   string
+		severity string
+		content  string
+	}{
+		{
+			name:     "plain",
+			review:   "1. SQL injection in query.\n\nSEVERITY: high",
+			severity: "high",
+			content:  "1. SQL injection in query.",
+		},
+		{
+			name:     "markdown",
+			review:   "Looks good.\n\n**Severity:** Low",
+			severity: "low",
+			content:  "Looks good.",
+		},
+		{
+			name:     "missing",
+			review:   "Looks good.",
+			severity: "",
+			content:  "Looks good.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			severity, content := ParseSeverity(tt.review)
+			if severity != tt.severity || content != tt.content {
+				t.Errorf("ParseSeverity() = %q, %q, want %q, %q", severity, content, tt.severity, tt.content)
+			}
+		})
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	if !SeverityAtLeast("high", "high") || !SeverityAtLeast("high", "medium") {
+		t.Error("SeverityAtLeast() = false, want true")
+	}
+	if SeverityAtLeast("low", "high") || SeverityAtLeast("", "high") || SeverityAtLeast("high", "urgent") {
+		t.Error("SeverityAtLeast() = true, want false")
+	}
+}
@@ -0,0 +1,313 @@
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Rule detects a kind of secret, like the rules of gitleaks.
+type Rule struct {
+	ID    string
+	Regex *regexp.Regexp
+	// SecretGroup is the capturing group holding the secret, 0 masks the whole match.
+	SecretGroup int
+	// Entropy is the minimum Shannon entropy of the secret, 0 disables the check.
+	// It filters out placeholders like `password = changeme`.
+	Entropy float64
+	// Stopwords keep the secrets containing one of them, ignoring the case, like `os.Getenv`.
+	Stopwords []string
+	// Allowlist keeps the secrets matching it, like call or selector expressions.
+	Allowlist *regexp.Regexp
+}
+
+// Finding is a secret masked in the diff.
+type Finding struct {
+	RuleID string
+	File   string
+	// Line is the line number in the new file, or the old file for deleted lines.
+	Line int
+}
+
+func (f Finding) String() string {
+	if f.File == "" {
+		return f.RuleID
+	}
+	return fmt.Sprintf("%s:%d [%s]", f.File, f.Line, f.RuleID)
+}
+
+// PrivateKeyRule is the rule ID of the private key blocks, masked from the BEGIN to the END line.
+const PrivateKeyRule = "private-key"
+
+// HighEntropyRule is the rule ID of the random looking strings not matched by other rules.
+const HighEntropyRule = "high-entropy-string"
+
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN[A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END[A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`)
+	// candidates for the high entropy check, like base64 or hex encoded keys
+	entropyToken = regexp.MustCompile(`[A-Za-z0-9+/_=-]{24,}`)
+	hunkHeader   = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+)
+
+// quote matches the quotes of a string literal in Go, JavaScript, Python, YAML and others.
+const quote = "[\"'`]"
+
+// highEntropy is the minimum Shannon entropy of the strings masked by the high entropy rule.
+// Hex encoded hashes like commit SHAs stay below it.
+const highEntropy = 4.5
+
+// DefaultRules returns the built-in rules for API keys, tokens, JWTs,
+// passwords in connection strings and assignments of secrets.
+func DefaultRules() []Rule {
+	return []Rule{
+		{ID: "aws-access-key-id", Regex: regexp.MustCompile(`\b((?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16})\b`), SecretGroup: 1},
+		{ID: "github-token", Regex: regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`), SecretGroup: 1},
+		{ID: "gitlab-token", Regex: regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20})\b`), SecretGroup: 1},
+		{ID: "slack-token", Regex: regexp.MustCompile(`\b(xox[baprs]-[A-Za-z0-9-]{10,})\b`), SecretGroup: 1},
+		{ID: "openai-api-key", Regex: regexp.MustCompile(`\b(sk-(?:proj-)?[A-Za-z0-9_-]{20,})\b`), SecretGroup: 1},
+		{ID: "stripe-key", Regex: regexp.MustCompile(`\b((?:sk|rk)_(?:live|test)_[A-Za-z0-9]{16,})\b`), SecretGroup: 1},
+		{ID: "google-api-key", Regex: regexp.MustCompile(`\b(AIza[A-Za-z0-9_-]{35})\b`), SecretGroup: 1},
+		{ID: "jwt", Regex: regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})`), SecretGroup: 1},
+		{ID: "connection-string-password", Regex: regexp.MustCompile(`\b[A-Za-z][A-Za-z0-9+.-]*://[^:/\s@]+:([^@\s/]+)@`), SecretGroup: 1},
+		{
+			// only string literals, the names of secrets are often assigned calls like `generateToken(ctx)`
+			ID: "generic-secret",
+			Regex: regexp.MustCompile(`(?i)(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|client[_-]?secret)[\w.-]*["']?\s*(?::=|[:=]|=>)\s*` +
+				quote + `([^\s"'` + "`" + `]{8,})` + quote),
+			SecretGroup: 1,
+			Entropy:     3.5,
+			Stopwords:   []string{"os.getenv", "viper.", "process.env", "${", "{{", "<", "example", "changeme", "placeholder"},
+			Allowlist:   regexp.MustCompile(`\(|\.[A-Za-z_]`),
+		},
+	}
+}
+
+// ParseRules parses the user rules written as `id=regex`, e.g. `internal-token=itk_[a-z0-9]{32}`.
+// If the regex has a capturing group, only the first group is masked.
+func ParseRules(vals []string) ([]Rule, error) {
+	rules := []Rule{}
+	for _, val := range vals {
+		id, pattern, ok := strings.Cut(val, "=")
+		id = strings.TrimSpace(id)
+		pattern = strings.TrimSpace(pattern)
+		if !ok || id == "" || pattern == "" {
+			return nil, fmt.Errorf("invalid redact rule %q, expected id=regex", val)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact rule %q: %w", id, err)
+		}
+		rule := Rule{ID: id, Regex: re}
+		if re.NumSubexp() > 0 {
+			rule.SecretGroup = 1
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Entropy returns the Shannon entropy of the string in bits per character.
+func Entropy(val string) float64 {
+	if val == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	for _, r := range val {
+		counts[r]++
+	}
+	n := float64(len([]rune(val)))
+	entropy := 0.0
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Redactor masks the secrets found by the rules, keeping the values matching the allowlist.
+type Redactor struct {
+	rules     []Rule
+	allowlist []*regexp.Regexp
+}
+
+// New returns a redactor with the rules and the allowlist regular expressions.
+func New(rules []Rule, allowlist []string) (*Redactor, error) {
+	r := &Redactor{rules: rules}
+	for _, pattern := range allowlist {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact allowlist %q: %w", pattern, err)
+		}
+		r.allowlist = append(r.allowlist, re)
+	}
+	return r, nil
+}
+
+func (r *Redactor) allowed(secret string) bool {
+	for _, re := range r.allowlist {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// skip reports whether the secret contains a stopword or matches the allowlist of the rule.
+func (rule Rule) skip(secret string) bool {
+	lower := strings.ToLower(secret)
+	for _, word := range rule.Stopwords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return rule.Allowlist != nil && rule.Allowlist.MatchString(secret)
+}
+
+// placeholder replaces a secret in the prompt.
+func placeholder(id string) string {
+	return "[REDACTED:" + id + "]"
+}
+
+// looksRandom reports whether the token is a random string like a key rather than a word,
+// an identifier or a path.
+func looksRandom(token string) bool {
+	if !strings.ContainsAny(token, "0123456789") {
+		return false
+	}
+	if strings.Contains(token, "/") && !strings.ContainsAny(token, "+=") {
+		return false
+	}
+	return Entropy(token) >= highEntropy
+}
+
+// line masks the secrets of a line and returns the IDs of the rules which matched.
+func (r *Redactor) line(val string) (string, []string) {
+	found := []string{}
+	for _, rule := range r.rules {
+		out := strings.Builder{}
+		last := 0
+		for _, loc := range rule.Regex.FindAllStringSubmatchIndex(val, -1) {
+			start, end := loc[0], loc[1]
+			if rule.SecretGroup > 0 && len(loc) > 2*rule.SecretGroup+1 && loc[2*rule.SecretGroup] >= 0 {
+				start, end = loc[2*rule.SecretGroup], loc[2*rule.SecretGroup+1]
+			}
+			secret := val[start:end]
+			if strings.HasPrefix(secret, "[REDACTED:") || r.allowed(secret) {
+				continue
+			}
+			if rule.Entropy > 0 && Entropy(secret) < rule.Entropy {
+				continue
+			}
+			if rule.skip(secret) {
+				continue
+			}
+			out.WriteString(val[last:start] + placeholder(rule.ID))
+			last = end
+		}
+		if last > 0 {
+			out.WriteString(val[last:])
+			val = out.String()
+			found = append(found, rule.ID)
+		}
+	}
+
+	matched := false
+	val = entropyToken.ReplaceAllStringFunc(val, func(token string) string {
+		if !looksRandom(token) || r.allowed(token) {
+			return token
+		}
+		matched = true
+		return placeholder(HighEntropyRule)
+	})
+	if matched {
+		found = append(found, HighEntropyRule)
+	}
+
+	return val, found
+}
+
+// Diff masks the secrets of the unified diff, and returns the masked diff and the findings.
+// The diff headers are kept, so the files and line numbers stay the same.
+func (r *Redactor) Diff(diff string) (string, []Finding) {
+	findings := []Finding{}
+	lines := strings.Split(diff, "\n")
+	file := ""
+	oldRemaining, newRemaining := 0, 0
+	oldNumber, newNumber := 0, 0
+	inPrivateKey := false
+
+	for i, val := range lines {
+		// headers between the hunks, counted with the hunk header so `--- ` lines are content
+		if oldRemaining <= 0 && newRemaining <= 0 {
+			inPrivateKey = false
+			switch {
+			case strings.HasPrefix(val, "--- "), strings.HasPrefix(val, "+++ "):
+				name := strings.TrimRight(val[4:], "\t")
+				if name != "/dev/null" {
+					file = name[strings.Index(name, "/")+1:]
+				}
+			case strings.HasPrefix(val, "@@ "):
+				if match := hunkHeader.FindStringSubmatch(val); match != nil {
+					oldNumber, _ = strconv.Atoi(match[1])
+					newNumber, _ = strconv.Atoi(match[3])
+					oldRemaining, newRemaining = 1, 1
+					if match[2] != "" {
+						oldRemaining, _ = strconv.Atoi(match[2])
+					}
+					if match[4] != "" {
+						newRemaining, _ = strconv.Atoi(match[4])
+					}
+				}
+			}
+			continue
+		}
+
+		number := newNumber
+		switch {
+		case strings.HasPrefix(val, "-"):
+			number = oldNumber
+			oldNumber++
+			oldRemaining--
+		case strings.HasPrefix(val, "+"):
+			newNumber++
+			newRemaining--
+		case strings.HasPrefix(val, `\`):
+			continue
+		default:
+			oldNumber++
+			newNumber++
+			oldRemaining--
+			newRemaining--
+		}
+
+		// mask the whole private key block, the base64 lines don't match any rule alone
+		if !inPrivateKey && privateKeyBegin.MatchString(val) {
+			inPrivateKey = true
+			findings = append(findings, Finding{RuleID: PrivateKeyRule, File: file, Line: number})
+		}
+		if inPrivateKey {
+			if privateKeyEnd.MatchString(val) {
+				inPrivateKey = false
+			}
+			if val != "" {
+				lines[i] = val[:1] + placeholder(PrivateKeyRule)
+			}
+			continue
+		}
+
+		if val == "" {
+			continue
+		}
+		// keep the +, - or space marker out of the matches, so the line type can't change
+		masked, ids := r.line(val[1:])
+		for _, id := range ids {
+			findings = append(findings, Finding{RuleID: id, File: file, Line: number})
+		}
+		lines[i] = val[:1] + masked
+	}
+
+	return strings.Join(lines, "\n"), findings
+}
@@ -0,0 +1,120 @@
+package usage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/openai"
+)
+
+// Limit is a spending limit in tokens or in USD.
+type Limit struct {
+	Tokens int
+	Cost   float64
+}
+
+// IsZero reports whether there is no limit.
+func (l Limit) IsZero() bool {
+	return l.Tokens == 0 && l.Cost == 0
+}
+
+func (l Limit) String() string {
+	if l.Cost > 0 {
+		return "$" + strconv.FormatFloat(l.Cost, 'f', -1, 64)
+	}
+	return strconv.Itoa(l.Tokens) + " tokens"
+}
+
+// ParseLimit parses a limit in USD like `$5`, `5usd` or `5 USD`, or in tokens like `200000`, `200k` or `2m`.
+// An empty value is no limit. A zero limit is an error rather than no limit, so the calls are refused.
+func ParseLimit(val string) (Limit, error) {
+	val = strings.ToLower(strings.TrimSpace(val))
+	if val == "" {
+		return Limit{}, nil
+	}
+
+	if cost := strings.TrimSpace(strings.TrimSuffix(strings.Trim(val, "$"), "usd")); cost != val {
+		v, err := strconv.ParseFloat(cost, 64)
+		if err != nil || v < 0 {
+			return Limit{}, fmt.Errorf("invalid budget %q, expected an amount like $5 or tokens like 200k", val)
+		}
+		if v == 0 {
+			return Limit{}, errZeroLimit(val)
+		}
+		return Limit{Cost: v}, nil
+	}
+
+	tokens, unit := val, 1
+	switch {
+	case strings.HasSuffix(val, "k"):
+		tokens, unit = strings.TrimSuffix(val, "k"), 1000
+	case strings.HasSuffix(val, "m"):
+		tokens, unit = strings.TrimSuffix(val, "m"), 1000000
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(tokens), 64)
+	if err != nil || v < 0 {
+		return Limit{}, fmt.Errorf("invalid budget %q, expected an amount like $5 or tokens like 200k", val)
+	}
+	if int(v*float64(unit)) == 0 {
+		return Limit{}, errZeroLimit(val)
+	}
+	return Limit{Tokens: int(v * float64(unit))}, nil
+}
+
+func errZeroLimit(val string) error {
+	return fmt.Errorf("budget %q refuses every call, unset it for no limit", val)
+}
+
+// Budget limits the spend of a day and a month.
+type Budget struct {
+	Daily   Limit
+	Monthly Limit
+}
+
+// Check returns an error if the next call could exceed the daily or monthly limit,
+// adding its tokens and cost to the spend of the records in the same period.
+// A limit in USD can't be checked for a model without a price, so the call is refused.
+// The past records of models without a price are not counted.
+func (b Budget) Check(records []Record, next Record, prices map[string]openai.Price) error {
+	for _, limit := range []struct {
+		period string
+		Limit
+	}{
+		{Daily, b.Daily},
+		{Monthly, b.Monthly},
+	} {
+		if limit.IsZero() {
+			continue
+		}
+
+		key := PeriodKey(next.Time, limit.period)
+		tokens, cost := 0, 0.0
+		for _, r := range records {
+			if PeriodKey(r.Time, limit.period) != key {
+				continue
+			}
+			tokens += r.TotalTokens
+			c, _ := Cost(r, prices)
+			cost += c
+		}
+		nextCost, ok := Cost(next, prices)
+		if limit.Cost > 0 && !ok {
+			return fmt.Errorf("%s budget of %s can't be checked: the %s model has no price, "+
+				"add it with `codegpt config set usage.prices %s=prompt/completion`",
+				limit.period, limit.Limit, next.Model, next.Model)
+		}
+		nextTokens := next.PromptTokens + next.CompletionTokens
+
+		switch {
+		case limit.Cost > 0 && cost+nextCost > limit.Cost:
+			return fmt.Errorf("%s budget of %s exceeded: $%.4f spent, the next %s call may cost up to $%.4f",
+				limit.period, limit.Limit, cost, next.Model, nextCost)
+		case limit.Tokens > 0 && tokens+nextTokens > limit.Tokens:
+			return fmt.Errorf("%s budget of %s exceeded: %d tokens spent, the next call may use up to %d tokens",
+				limit.period, limit.Limit, tokens, nextTokens)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/appleboy/CodeGPT/openai"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		val     string
+		want    Limit
+		wantErr bool
+	}{
+		{"", Limit{}, false},
+		{"$5", Limit{Cost: 5}, false},
+		{"2.5 USD", Limit{Cost: 2.5}, false},
+		{"10usd", Limit{Cost: 10}, false},
+		{"200000", Limit{Tokens: 200000}, false},
+		{"200k", Limit{Tokens: 200000}, false},
+		{"1.5m", Limit{Tokens: 1500000}, false},
+		{"$abc", Limit{}, true},
+		{"ten", Limit{}, true},
+		// a zero budget is not the same as no budget
+		{"$0", Limit{}, true},
+		{"0", Limit{}, true},
+		{"0.0001k", Limit{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.val)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLimit(%q) = %v, %v, want %v", tt.val, got, err, tt.want)
+		}
+	}
+}
+
+func TestBudgetCheck(t *testing.T) {
+	now := time.Date(2023, 4, 15, 12, 0, 0, 0, time.Local)
+	prices := map[string]openai.Price{
+		"gpt-3.5-turbo": {Prompt: 0.002, Completion: 0.002},
+		"gpt-4":         {Prompt: 0.03, Completion: 0.06},
+	}
+	records := []Record{
+		// $0.09 and 2000 tokens today
+		{Time: now.Add(-time.Hour), Model: "gpt-4", PromptTokens: 1000, CompletionTokens: 1000, TotalTokens: 2000},
+		// $0.18 and 4000 tokens earlier this month
+		{Time: now.AddDate(0, 0, -5), Model: "gpt-4", PromptTokens: 2000, CompletionTokens: 2000, TotalTokens: 4000},
+		// last month is not counted
+		{Time: now.AddDate(0, -1, 0), Model: "gpt-4", PromptTokens: 100000, CompletionTokens: 100000, TotalTokens: 200000},
+	}
+	gpt4 := Record{Time: now, Model: "gpt-4", PromptTokens: 1000, CompletionTokens: 300}
+	gpt35 := Record{Time: now, Model: "gpt-3.5-turbo", PromptTokens: 1000, CompletionTokens: 300}
+	unknown := Record{Time: now, Model: "my-model", PromptTokens: 1000, CompletionTokens: 300}
+
+	tests := []struct {
+		name    string
+		budget  Budget
+		next    Record
+		wantErr bool
+	}{
+		{"no limit", Budget{}, gpt4, false},
+		{"daily cost left", Budget{Daily: Limit{Cost: 0.2}}, gpt4, false},
+		{"daily cost exceeded", Budget{Daily: Limit{Cost: 0.1}}, gpt4, true},
+		{"cheaper model fits", Budget{Daily: Limit{Cost: 0.1}}, gpt35, false},
+		{"monthly cost exceeded", Budget{Daily: Limit{Cost: 1}, Monthly: Limit{Cost: 0.3}}, gpt4, true},
+		{"daily tokens exceeded", Budget{Daily: Limit{Tokens: 3000}}, gpt35, true},
+		{"monthly tokens left", Budget{Monthly: Limit{Tokens: 8000}}, gpt35, false},
+		{"cost limit without price", Budget{Daily: Limit{Cost: 100}}, unknown, true},
+		{"token limit without price", Budget{Daily: Limit{Tokens: 8000}}, unknown, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.budget.Check(records, tt.next, prices)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
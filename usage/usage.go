@@ -0,0 +1,155 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/appleboy/CodeGPT/openai"
+)
+
+// Periods of the totals.
+const (
+	Daily   = "daily"
+	Monthly = "monthly"
+)
+
+// Record is the token usage of a call to the model.
+type Record struct {
+	Time             time.Time `json:"time"`
+	Command          string    `json:"command"`
+	Model            string    `json:"model"`
+	Repo             string    `json:"repo,omitempty"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+}
+
+// Ledger is a JSON Lines file keeping a record of every call.
+type Ledger struct {
+	path string
+}
+
+// NewLedger returns the ledger stored in the file.
+func NewLedger(path string) *Ledger {
+	return &Ledger{path: path}
+}
+
+// Path returns the path of the ledger file.
+func (l *Ledger) Path() string {
+	return l.path
+}
+
+// Append adds the record to the end of the ledger, creating the file if needed.
+func (l *Ledger) Append(r Record) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Records returns the records of the ledger, or none if the file doesn't exist.
+func (l *Ledger) Records() ([]Record, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Record{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records := []Record{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		r := Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", l.path, n, err)
+		}
+		records = append(records, r)
+	}
+
+	return records, scanner.Err()
+}
+
+// Total is the usage of a model in a period like `2023-04-01` or `2023-04`.
+type Total struct {
+	Period           string
+	Model            string
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	// Cost is the estimated cost in USD, unknown if the model has no price.
+	Cost    float64
+	Unknown bool
+}
+
+// PeriodKey returns the daily or monthly period of the time.
+func PeriodKey(t time.Time, period string) string {
+	if period == Monthly {
+		return t.Local().Format("2006-01")
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+// Cost returns the estimated cost in USD of the record and whether the model has a price.
+func Cost(r Record, prices map[string]openai.Price) (float64, bool) {
+	price, ok := prices[r.Model]
+	if !ok {
+		return 0, false
+	}
+	return price.Cost(r.PromptTokens, r.CompletionTokens), true
+}
+
+// Totals sums the records by period and model, sorted by period and model.
+func Totals(records []Record, period string, prices map[string]openai.Price) []Total {
+	totals := map[[2]string]*Total{}
+	for _, r := range records {
+		key := [2]string{PeriodKey(r.Time, period), r.Model}
+		t, ok := totals[key]
+		if !ok {
+			t = &Total{Period: key[0], Model: key[1]}
+			totals[key] = t
+		}
+		t.Requests++
+		t.PromptTokens += r.PromptTokens
+		t.CompletionTokens += r.CompletionTokens
+		t.TotalTokens += r.TotalTokens
+		cost, ok := Cost(r, prices)
+		t.Cost += cost
+		t.Unknown = t.Unknown || !ok
+	}
+
+	list := []Total{}
+	for _, t := range totals {
+		list = append(list, *t)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Period != list[j].Period {
+			return list[i].Period < list[j].Period
+		}
+		return list[i].Model < list[j].Model
+	})
+
+	return list
+}
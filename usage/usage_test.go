@@ -0,0 +1,82 @@
+package usage
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/appleboy/CodeGPT/openai"
+)
+
+func TestLedger(t *testing.T) {
+	l := NewLedger(filepath.Join(t.TempDir(), "codegpt", "usage.jsonl"))
+
+	records, err := l.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 {
+		t.Errorf("Records() = %v, want none before the first call", records)
+	}
+
+	day := time.Date(2023, 4, 1, 12, 0, 0, 0, time.Local)
+	for _, r := range []Record{
+		{Time: day, Command: "commit", Model: "gpt-3.5-turbo", PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500},
+		{Time: day, Command: "review", Model: "gpt-4", PromptTokens: 2000, CompletionTokens: 1000, TotalTokens: 3000},
+		{Time: day.AddDate(0, 0, 1), Command: "commit", Model: "gpt-3.5-turbo", PromptTokens: 1000, CompletionTokens: 1000, TotalTokens: 2000},
+		{Time: day.AddDate(0, 0, 1), Command: "commit", Model: "my-model", PromptTokens: 10, CompletionTokens: 10, TotalTokens: 20},
+	} {
+		if err := l.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err = l.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 || records[1].Command != "review" || !records[1].Time.Equal(day) {
+		t.Fatalf("Records() = %v, want the 4 appended records", records)
+	}
+
+	prices := map[string]openai.Price{
+		"gpt-3.5-turbo": {Prompt: 0.002, Completion: 0.002},
+		"gpt-4":         {Prompt: 0.03, Completion: 0.06},
+	}
+	tests := []struct {
+		period string
+		want   []Total
+	}{
+		{
+			Daily,
+			[]Total{
+				{Period: "2023-04-01", Model: "gpt-3.5-turbo", Requests: 1, PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500, Cost: 0.003},
+				{Period: "2023-04-01", Model: "gpt-4", Requests: 1, PromptTokens: 2000, CompletionTokens: 1000, TotalTokens: 3000, Cost: 0.12},
+				{Period: "2023-04-02", Model: "gpt-3.5-turbo", Requests: 1, PromptTokens: 1000, CompletionTokens: 1000, TotalTokens: 2000, Cost: 0.004},
+				{Period: "2023-04-02", Model: "my-model", Requests: 1, PromptTokens: 10, CompletionTokens: 10, TotalTokens: 20, Unknown: true},
+			},
+		},
+		{
+			Monthly,
+			[]Total{
+				{Period: "2023-04", Model: "gpt-3.5-turbo", Requests: 2, PromptTokens: 2000, CompletionTokens: 1500, TotalTokens: 3500, Cost: 0.007},
+				{Period: "2023-04", Model: "gpt-4", Requests: 1, PromptTokens: 2000, CompletionTokens: 1000, TotalTokens: 3000, Cost: 0.12},
+				{Period: "2023-04", Model: "my-model", Requests: 1, PromptTokens: 10, CompletionTokens: 10, TotalTokens: 20, Unknown: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := Totals(records, tt.period, prices)
+		if len(got) != len(tt.want) {
+			t.Fatalf("Totals(%s) = %v, want %v", tt.period, got, tt.want)
+		}
+		for i := range got {
+			// compare the costs rounded to avoid floating point errors
+			got[i].Cost = math.Round(got[i].Cost*1e6) / 1e6
+			if got[i] != tt.want[i] {
+				t.Errorf("Totals(%s)[%d] = %+v, want %+v", tt.period, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
@@ -4,20 +4,116 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
-	"html/template"
 	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
 )
 
 // Data define a custom type for the template data.
 type Data map[string]interface{}
 
+// SystemTemplateName is the name of the block which defines the system message of a prompt template,
+// e.g. {{ define "system" }}You are an expert programmer.{{ end }}
+const SystemTemplateName = "system"
+
 var (
 	templates    map[string]*template.Template
 	templatesDir = "templates"
 )
 
+// funcMap is the helper function library available in all templates.
+// The string argument comes last so the helpers can be used in pipelines,
+// e.g. {{ .summarize_message | wrap 72 | indent 2 }}.
+var funcMap = template.FuncMap{
+	"truncate":     truncate,
+	"indent":       indent,
+	"wrap":         wrap,
+	"join":         join,
+	"upper":        strings.ToUpper,
+	"lower":        strings.ToLower,
+	"firstLine":    firstLine,
+	"regexReplace": regexReplace,
+}
+
+// truncate returns the first n characters of s.
+func truncate(n int, s string) string {
+	r := []rune(s)
+	if n < 0 || len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// indent prefixes every non-empty line of s with n spaces.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrap wraps every line of s at n columns without breaking words.
+func wrap(n int, s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		words := strings.Fields(line)
+		if n <= 0 || len(words) == 0 || len([]rune(line)) <= n {
+			continue
+		}
+		// keep the leading whitespace of the line, e.g. list indentation
+		prefix := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		var b strings.Builder
+		width := 0
+		for j, word := range words {
+			w := len([]rune(word))
+			switch {
+			case j == 0:
+				b.WriteString(prefix)
+				width = len(prefix)
+			case width+1+w > n:
+				b.WriteString("\n" + prefix)
+				width = len(prefix)
+			default:
+				b.WriteString(" ")
+				width++
+			}
+			b.WriteString(word)
+			width += w
+		}
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// join concatenates the items with sep.
+func join(sep string, items []string) string {
+	return strings.Join(items, sep)
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// regexReplace replaces all matches of the pattern in s with repl.
+func regexReplace(pattern, repl, s string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(s, repl), nil
+}
+
 func NewTemplateByString(format string, data map[string]interface{}) (string, error) {
-	t, err := template.New("message").Parse(format)
+	t, err := template.New("message").Funcs(funcMap).Parse(format)
 	if err != nil {
 		return "", err
 	}
@@ -52,6 +148,27 @@ func GetTemplateByString(name string, data map[string]interface{}) (string, erro
 	return tpl.String(), err
 }
 
+// GetSystemTemplateByString returns the parsed system block of the template as a string.
+// If the template doesn't define a system block, it returns an empty string.
+func GetSystemTemplateByString(name string, data map[string]interface{}) (string, error) {
+	t, ok := templates[name]
+	if !ok {
+		return "", fmt.Errorf("template %s not found", name)
+	}
+
+	st := t.Lookup(SystemTemplateName)
+	if st == nil {
+		return "", nil
+	}
+
+	var tpl bytes.Buffer
+	if err := st.Execute(&tpl, data); err != nil {
+		return "", err
+	}
+
+	return tpl.String(), nil
+}
+
 // GetTemplateByBytes returns the parsed template as a byte.
 func GetTemplateByBytes(name string, data map[string]interface{}) ([]byte, error) {
 	tpl, err := processTemplate(name, data)
@@ -73,7 +190,7 @@ func LoadTemplates(files embed.FS) error {
 			continue
 		}
 
-		pt, err := template.ParseFS(files, templatesDir+"/"+tmpl.Name())
+		pt, err := template.New(tmpl.Name()).Funcs(funcMap).ParseFS(files, templatesDir+"/"+tmpl.Name())
 		if err != nil {
 			return err
 		}
@@ -82,3 +199,37 @@ func LoadTemplates(files embed.FS) error {
 	}
 	return nil
 }
+
+// LoadTemplatesFromDir loads the `*.tmpl` files of the given directory which override
+// a loaded template with the same file name, so a typo can't silently add an unused template.
+// It returns the names of the loaded templates and of the unknown templates which were skipped.
+// Other files are ignored.
+func LoadTemplatesFromDir(dir string) ([]string, []string, error) {
+	if templates == nil {
+		templates = make(map[string]*template.Template)
+	}
+	tmplFiles, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names, unknown := []string{}, []string{}
+	for _, tmpl := range tmplFiles {
+		if tmpl.IsDir() || filepath.Ext(tmpl.Name()) != ".tmpl" {
+			continue
+		}
+		if _, ok := templates[tmpl.Name()]; !ok {
+			unknown = append(unknown, tmpl.Name())
+			continue
+		}
+
+		pt, err := template.New(tmpl.Name()).Funcs(funcMap).ParseFiles(filepath.Join(dir, tmpl.Name()))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		templates[tmpl.Name()] = pt
+		names = append(names, tmpl.Name())
+	}
+	return names, unknown, nil
+}
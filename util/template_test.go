@@ -0,0 +1,178 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"text/template"
+)
+
+// knownTemplates registers empty templates, like the built-in templates loaded at init.
+func knownTemplates(names ...string) {
+	if templates == nil {
+		templates = make(map[string]*template.Template)
+	}
+	for _, name := range names {
+		templates[name] = template.Must(template.New(name).Parse(""))
+	}
+}
+
+func TestLoadTemplatesFromDir(t *testing.T) {
+	knownTemplates("hello.tmpl")
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"hello.tmpl": "hello {{ .name }}",
+		"helo.tmpl":  "typo {{ .name }}",
+		"README.md":  "# my prompts",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names, unknown, err := LoadTemplatesFromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"hello.tmpl"}) {
+		t.Fatalf("LoadTemplatesFromDir() names = %v, want [hello.tmpl]", names)
+	}
+	if !reflect.DeepEqual(unknown, []string{"helo.tmpl"}) {
+		t.Errorf("LoadTemplatesFromDir() unknown = %v, want [helo.tmpl]", unknown)
+	}
+	if _, err := GetTemplateByString("helo.tmpl", nil); err == nil {
+		t.Error("GetTemplateByString(helo.tmpl) error = nil, want the unknown template to be skipped")
+	}
+
+	got, err := GetTemplateByString("hello.tmpl", Data{"name": "codegpt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "hello codegpt" {
+		t.Errorf("GetTemplateByString() = %q, want %q", got, "hello codegpt")
+	}
+
+	// override the template with the same name
+	if err := os.WriteFile(filepath.Join(dir, "hello.tmpl"), []byte("hi {{ .name }}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadTemplatesFromDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	got, err = GetTemplateByString("hello.tmpl", Data{"name": "codegpt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "hi codegpt" {
+		t.Errorf("GetTemplateByString() = %q, want %q", got, "hi codegpt")
+	}
+}
+
+func TestNewTemplateByString(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   Data
+		want   string
+	}{
+		{
+			name:   "no html escape",
+			format: "{{ .msg }}",
+			data:   Data{"msg": `a < b && "c"`},
+			want:   `a < b && "c"`,
+		},
+		{
+			name:   "truncate",
+			format: "{{ .msg | truncate 5 }}",
+			data:   Data{"msg": "hello world"},
+			want:   "hello",
+		},
+		{
+			name:   "indent",
+			format: "{{ .msg | indent 2 }}",
+			data:   Data{"msg": "- foo\n\n- bar"},
+			want:   "  - foo\n\n  - bar",
+		},
+		{
+			name:   "wrap",
+			format: "{{ .msg | wrap 10 }}",
+			data:   Data{"msg": "  the quick brown fox"},
+			want:   "  the\n  quick\n  brown\n  fox",
+		},
+		{
+			name:   "join",
+			format: `{{ .items | join ", " }}`,
+			data:   Data{"items": []string{"foo", "bar"}},
+			want:   "foo, bar",
+		},
+		{
+			name:   "upper and lower",
+			format: "{{ .msg | upper }} {{ .msg | lower }}",
+			data:   Data{"msg": "Feat"},
+			want:   "FEAT feat",
+		},
+		{
+			name:   "first line",
+			format: "{{ .msg | firstLine }}",
+			data:   Data{"msg": "title\n\nbody"},
+			want:   "title",
+		},
+		{
+			name:   "regex replace",
+			format: `{{ .msg | regexReplace "\\.$" "" }}`,
+			data:   Data{"msg": "add cache."},
+			want:   "add cache",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTemplateByString(tt.format, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("NewTemplateByString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSystemTemplateByString(t *testing.T) {
+	knownTemplates("system.tmpl", "user.tmpl")
+	dir := t.TempDir()
+	content := `{{ define "system" }}You are {{ .persona }}.{{ end }}Summarize {{ .diff }}`
+	if err := os.WriteFile(filepath.Join(dir, "system.tmpl"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "user.tmpl"), []byte("Summarize {{ .diff }}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadTemplatesFromDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	data := Data{"persona": "an expert programmer", "diff": "the diff"}
+	system, err := GetSystemTemplateByString("system.tmpl", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if system != "You are an expert programmer." {
+		t.Errorf("GetSystemTemplateByString() = %q, want %q", system, "You are an expert programmer.")
+	}
+	user, err := GetTemplateByString("system.tmpl", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user != "Summarize the diff" {
+		t.Errorf("GetTemplateByString() = %q, want %q", user, "Summarize the diff")
+	}
+
+	system, err = GetSystemTemplateByString("user.tmpl", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if system != "" {
+		t.Errorf("GetSystemTemplateByString() = %q, want empty string", system)
+	}
+}